@@ -1,14 +1,55 @@
 // Package assets bundles all required assets (images, fonts) into a single package.
 package assets
 
-import "embed"
+import (
+	"embed"
+	"image"
+)
 
 // Backgrounds contains all background images used for the achievements.
 //
 //go:embed backgrounds/*.png
 var Backgrounds embed.FS
 
+// Frames contains empty backgrounds icons are drawn on.
+//
+//go:embed frames/*.png
+var Frames embed.FS
+
 // FontFile contains the font used for the achievement title and description.
 //
 //go:embed font.ttf
 var FontFile []byte
+
+// Glint contains the texture added on top of enchanted icons.
+// It tiles seamlessly and is moved diagonally to animate the glint.
+//
+//go:embed glint.png
+var Glint []byte
+
+// BannerPatterns contains the masks of all banner patterns, named by their ID.
+// Each mask covers the front of a banner; its alpha selects the pixels dyed in the pattern's colour.
+//
+//go:embed banners/*.png
+var BannerPatterns embed.FS
+
+// Dimensions of the front of a banner, as the game's texture shows it.
+const (
+	BannerWidth  = 20
+	BannerHeight = 40
+)
+
+// Dimensions of backgrounds and frames, twice the size of the game's achievement toasts.
+const (
+	BackgroundWidth  = 320
+	BackgroundHeight = 64
+)
+
+// IconBounds is the area of backgrounds and frames containing the icon.
+var IconBounds = image.Rect(16, 16, 48, 48)
+
+// Names of the frame icons are drawn on and the font used for title and text, within each namespace.
+const (
+	DefaultFrame = "default"
+	DefaultFont  = "default"
+)
@@ -0,0 +1,43 @@
+package assets
+
+import "image/color"
+
+// DyeColors maps the IDs of the game's dye colours to the colours they tint banners in.
+var DyeColors = map[string]color.RGBA{
+	"white":      {R: 0xf9, G: 0xff, B: 0xfe, A: 0xff},
+	"orange":     {R: 0xf9, G: 0x80, B: 0x1d, A: 0xff},
+	"magenta":    {R: 0xc7, G: 0x4e, B: 0xbd, A: 0xff},
+	"light_blue": {R: 0x3a, G: 0xb3, B: 0xda, A: 0xff},
+	"yellow":     {R: 0xfe, G: 0xd8, B: 0x3d, A: 0xff},
+	"lime":       {R: 0x80, G: 0xc7, B: 0x1f, A: 0xff},
+	"pink":       {R: 0xf3, G: 0x8b, B: 0xaa, A: 0xff},
+	"gray":       {R: 0x47, G: 0x4f, B: 0x52, A: 0xff},
+	"light_gray": {R: 0x9d, G: 0x9d, B: 0x97, A: 0xff},
+	"cyan":       {R: 0x16, G: 0x9c, B: 0x9c, A: 0xff},
+	"purple":     {R: 0x89, G: 0x32, B: 0xb8, A: 0xff},
+	"blue":       {R: 0x3c, G: 0x44, B: 0xaa, A: 0xff},
+	"brown":      {R: 0x83, G: 0x54, B: 0x32, A: 0xff},
+	"green":      {R: 0x5e, G: 0x7c, B: 0x16, A: 0xff},
+	"red":        {R: 0xb0, G: 0x2e, B: 0x26, A: 0xff},
+	"black":      {R: 0x1d, G: 0x1d, B: 0x21, A: 0xff},
+}
+
+// DyeTextColors maps the IDs of the game's dye colours to the colours they dye the text of signs in.
+var DyeTextColors = map[string]color.RGBA{
+	"white":      {R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	"orange":     {R: 0xff, G: 0x68, B: 0x1f, A: 0xff},
+	"magenta":    {R: 0xff, G: 0x00, B: 0xff, A: 0xff},
+	"light_blue": {R: 0x9a, G: 0xc0, B: 0xcd, A: 0xff},
+	"yellow":     {R: 0xff, G: 0xff, B: 0x00, A: 0xff},
+	"lime":       {R: 0xbf, G: 0xff, B: 0x00, A: 0xff},
+	"pink":       {R: 0xff, G: 0x69, B: 0xb4, A: 0xff},
+	"gray":       {R: 0x80, G: 0x80, B: 0x80, A: 0xff},
+	"light_gray": {R: 0xd3, G: 0xd3, B: 0xd3, A: 0xff},
+	"cyan":       {R: 0x00, G: 0xff, B: 0xff, A: 0xff},
+	"purple":     {R: 0xa0, G: 0x20, B: 0xf0, A: 0xff},
+	"blue":       {R: 0x00, G: 0x00, B: 0xff, A: 0xff},
+	"brown":      {R: 0x8b, G: 0x45, B: 0x13, A: 0xff},
+	"green":      {R: 0x00, G: 0xff, B: 0x00, A: 0xff},
+	"red":        {R: 0xff, G: 0x00, B: 0x00, A: 0xff},
+	"black":      {R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+}
@@ -0,0 +1,46 @@
+package assets
+
+// IconMappings are the mappings of legacy icon IDs and of item and block IDs to backgrounds.
+// They are never modified, so both can be replaced together by swapping the whole value.
+type IconMappings struct {
+	legacy map[string]string
+	items  map[string]string
+}
+
+// DefaultIconMappings are the built-in mappings.
+var DefaultIconMappings = NewIconMappings(nil, nil)
+
+// NewIconMappings returns the built-in mappings extended by the given additional ones.
+// Vanilla IDs of the additional item mappings may be given with or without their namespace.
+func NewIconMappings(legacy map[string]string, items map[string]string) *IconMappings {
+	mappings := &IconMappings{
+		legacy: make(map[string]string, len(LegacyIconMappings)+len(legacy)),
+		items:  make(map[string]string, len(ItemIconMappings)+len(items)),
+	}
+	for id, background := range LegacyIconMappings {
+		mappings.legacy[id] = background
+	}
+	for id, background := range legacy {
+		mappings.legacy[id] = background
+	}
+	for id, background := range ItemIconMappings {
+		mappings.items[id] = background
+	}
+	for id, background := range items {
+		mappings.items[trimMinecraftNamespace(id)] = background
+	}
+	return mappings
+}
+
+// LegacyIcon returns the background name the given legacy icon ID is mapped to.
+func (mappings *IconMappings) LegacyIcon(id string) (string, bool) {
+	background, exists := mappings.legacy[id]
+	return background, exists
+}
+
+// ItemIcon returns the background name the given item or block ID is mapped to.
+// Vanilla IDs are accepted with and without their namespace, e.g. "minecraft:diamond_sword" and "diamond_sword".
+func (mappings *IconMappings) ItemIcon(id string) (string, bool) {
+	background, exists := mappings.items[trimMinecraftNamespace(id)]
+	return background, exists
+}
@@ -0,0 +1,52 @@
+package assets
+
+import "strings"
+
+// ItemIconMappings maps vanilla item and block IDs without namespace to the filenames of the matching icons.
+// These are the built-in mappings; use IconMappings to look up the currently active ones.
+var ItemIconMappings = map[string]string{
+	"arrow":              "arrow",
+	"red_bed":            "bed",
+	"book":               "book",
+	"bow":                "bow",
+	"bucket":             "bucket",
+	"lava_bucket":        "bucket_lava",
+	"milk_bucket":        "bucket_milk",
+	"water_bucket":       "bucket_water",
+	"cake":               "cake",
+	"chest":              "chest",
+	"diamond_chestplate": "chestplate_diamond",
+	"iron_chestplate":    "chestplate_iron",
+	"coal":               "coal",
+	"cobweb":             "cobweb",
+	"cookie":             "cookie",
+	"crafting_table":     "crafting_table",
+	"creeper_head":       "creeper",
+	"diamond":            "diamond",
+	"iron_door":          "door_iron",
+	"oak_door":           "door_wood",
+	"fire":               "fire",
+	"flint_and_steel":    "flint_and_steel",
+	"furnace":            "furnace",
+	"gold_ingot":         "gold",
+	"grass_block":        "grass",
+	"iron_ingot":         "iron",
+	"pig_spawn_egg":      "spawn_egg",
+	"oak_planks":         "planks",
+	"potion":             "potion",
+	"rail":               "rail",
+	"redstone":           "redstone",
+	"oak_sign":           "sign",
+	"splash_potion":      "splash_potion",
+	"stone":              "stone",
+	"diamond_sword":      "sword_diamond",
+	"iron_sword":         "sword_iron",
+	"tnt":                "tnt",
+}
+
+// MinecraftNamespace is the namespace of vanilla IDs, which may be omitted.
+const MinecraftNamespace = "minecraft"
+
+func trimMinecraftNamespace(id string) string {
+	return strings.TrimPrefix(id, MinecraftNamespace+":")
+}
@@ -1,6 +1,7 @@
 package assets
 
 // LegacyIconMappings maps the old icon IDs to the new filenames.
+// These are the built-in mappings; use IconMappings to look up the currently active ones.
 var LegacyIconMappings = map[string]string{
 	"1":  "grass",
 	"2":  "diamond",
@@ -0,0 +1,311 @@
+package assets
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/png" // register the PNG format for image.Decode
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+)
+
+// list of errors returned when loading packs
+var (
+	ErrInvalidName      = errors.New("invalid name")
+	ErrInvalidDimension = errors.New("invalid image dimensions")
+)
+
+// namePattern matches valid names of namespaces and assets.
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// A Pack contains assets loaded at runtime from a directory or a zip file.
+// Its layout mirrors the embedded assets: backgrounds/*.png, frames/*.png, icons/*.png, blocks/*.json and font.ttf, all optional.
+//
+// Backgrounds and frames must be 320x64 pixels. Icons must be 16x16 or 32x32 pixels and are drawn on the pack's default frame.
+// Blocks are rendered as isometric icons from the textures they declare.
+// Names are the file names without extension; they are prefixed by the pack's namespace (e.g. "mypack:ruby") if it has one.
+type Pack struct {
+	Namespace   string
+	Path        string
+	Backgrounds map[string]image.Image
+	Frames      map[string]image.Image
+	Icons       map[string]image.Image
+	Blocks      map[string]Block
+	Font        *truetype.Font
+}
+
+// A Block declares the textures of a block's faces, which are rendered as isometric icon.
+// Textures are names of icons or backgrounds, whose icon is used then; the front and side default to the side and top respectively.
+type Block struct {
+	Top   string `json:"top"`
+	Side  string `json:"side,omitempty"`
+	Front string `json:"front,omitempty"`
+}
+
+// WithDefaults returns the block with missing faces replaced by their defaults.
+func (block Block) WithDefaults() Block {
+	if block.Side == "" {
+		block.Side = block.Top
+	}
+	if block.Front == "" {
+		block.Front = block.Side
+	}
+	return block
+}
+
+// Validate checks that the block declares a texture for its top.
+func (block Block) Validate() error {
+	if block.Top == "" {
+		return errors.New("missing texture of top")
+	}
+	return nil
+}
+
+// ParsePackSpec splits a pack given as "namespace=path" or "path" into its namespace and path.
+func ParsePackSpec(spec string) (namespace string, path string, err error) {
+	namespace, path, found := strings.Cut(spec, "=")
+	if !found {
+		return "", spec, nil
+	}
+	if !namePattern.MatchString(namespace) {
+		return "", "", fmt.Errorf("%w: namespace %q", ErrInvalidName, namespace)
+	}
+	if path == "" {
+		return "", "", fmt.Errorf("missing path of pack %q", namespace)
+	}
+	return namespace, path, nil
+}
+
+// LoadPack loads the pack at the given path, which is either a directory or a zip file.
+// All invalid assets are reported at once.
+func LoadPack(path string, namespace string) (Pack, error) {
+	if namespace != "" && !namePattern.MatchString(namespace) {
+		return Pack{}, fmt.Errorf("%w: namespace %q", ErrInvalidName, namespace)
+	}
+
+	fsys, closer, err := openPack(path)
+	if err != nil {
+		return Pack{}, err
+	}
+	defer closer.Close()
+
+	pack, err := ReadPack(fsys, namespace)
+	if err != nil {
+		return Pack{}, fmt.Errorf("loading pack %s: %w", path, err)
+	}
+	pack.Path = path
+	return pack, nil
+}
+
+// openPack opens the directory or zip file at the given path as file system.
+func openPack(path string) (fs.FS, io.Closer, error) {
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		archive, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening pack %s: %w", path, err)
+		}
+		return archive, archive, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening pack %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("opening pack %s: neither a directory nor a zip file", path)
+	}
+	return os.DirFS(path), io.NopCloser(nil), nil
+}
+
+// ReadPack reads a pack from the given file system.
+// Archives containing nothing but a single directory are read from within that directory.
+func ReadPack(fsys fs.FS, namespace string) (Pack, error) {
+	fsys, err := packRoot(fsys, "backgrounds", "frames", "icons")
+	if err != nil {
+		return Pack{}, err
+	}
+
+	pack := Pack{Namespace: namespace}
+	var errs []error
+	pack.Backgrounds, errs = readImages(fsys, "backgrounds", errs, CheckBackground)
+	pack.Frames, errs = readImages(fsys, "frames", errs, CheckBackground)
+	pack.Icons, errs = readImages(fsys, "icons", errs, CheckIcon)
+	pack.Blocks, errs = readBlocks(fsys, "blocks", errs)
+
+	content, err := fs.ReadFile(fsys, "font.ttf")
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		errs = append(errs, fmt.Errorf("reading font: %w", err))
+	default:
+		pack.Font, err = truetype.Parse(content)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parsing font: %w", err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Pack{}, err
+	}
+	return pack, nil
+}
+
+// ValidateName checks that the given asset name consists of lowercase letters, digits and underscores, optionally prefixed by a namespace ("mypack:ruby").
+func ValidateName(name string) error {
+	namespace, local, found := strings.Cut(name, ":")
+	if !found {
+		namespace, local = "", name
+	}
+	if !namePattern.MatchString(local) || (found && !namePattern.MatchString(namespace)) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// Name returns the name of the given asset within the pack's namespace.
+func (pack Pack) Name(name string) string {
+	if pack.Namespace == "" {
+		return name
+	}
+	return pack.Namespace + ":" + name
+}
+
+// packRoot returns the directory of the file system containing the pack.
+// It descends into the only directory of the file system, unless it is one of the given directories of a pack.
+func packRoot(fsys fs.FS, dirs ...string) (fs.FS, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading pack: %w", err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir() || slices.Contains(dirs, entries[0].Name()) {
+		return fsys, nil
+	}
+	return fs.Sub(fsys, entries[0].Name())
+}
+
+// readImages decodes all PNG files of the given directory, checks them and appends all errors to errs.
+// Other files are ignored.
+func readImages(fsys fs.FS, dir string, errs []error, check func(image.Image) error) (map[string]image.Image, []error) {
+	images := make(map[string]image.Image)
+	entries, err := fs.ReadDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return images, errs
+	}
+	if err != nil {
+		return images, append(errs, fmt.Errorf("reading %s: %w", dir, err))
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".png" {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".png")
+		file := path.Join(dir, entry.Name())
+		if !namePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf("%s: %w", file, ErrInvalidName))
+			continue
+		}
+
+		img, err := decodeImage(fsys, file)
+		if err == nil {
+			err = check(img)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		images[name] = img
+	}
+	return images, errs
+}
+
+// readBlocks decodes all JSON files of the given directory as blocks and appends all errors to errs.
+func readBlocks(fsys fs.FS, dir string, errs []error) (map[string]Block, []error) {
+	blocks := make(map[string]Block)
+	entries, err := fs.ReadDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return blocks, errs
+	}
+	if err != nil {
+		return blocks, append(errs, fmt.Errorf("reading %s: %w", dir, err))
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		file := path.Join(dir, entry.Name())
+		if !namePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf("%s: %w", file, ErrInvalidName))
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		var block Block
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&block); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		if err := block.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		blocks[name] = block
+	}
+	return blocks, errs
+}
+
+func decodeImage(fsys fs.FS, name string) (image.Image, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	return img, err
+}
+
+// CheckBackground checks the dimensions of a background or frame.
+func CheckBackground(img image.Image) error {
+	return CheckBackgroundSize(img.Bounds().Size())
+}
+
+// CheckBackgroundSize checks the size of a background or frame, e.g. before decoding it.
+func CheckBackgroundSize(size image.Point) error {
+	if size.X != BackgroundWidth || size.Y != BackgroundHeight {
+		return fmt.Errorf("%w: %dx%d instead of %dx%d", ErrInvalidDimension, size.X, size.Y, BackgroundWidth, BackgroundHeight)
+	}
+	return nil
+}
+
+// CheckIcon checks the dimensions of an icon.
+func CheckIcon(img image.Image) error {
+	return CheckIconSize(img.Bounds().Size())
+}
+
+// CheckIconSize checks the size of an icon, e.g. before decoding it.
+func CheckIconSize(size image.Point) error {
+	if size.X != size.Y || (size.X != IconBounds.Dx() && size.X != IconBounds.Dx()/2) {
+		return fmt.Errorf("%w: %dx%d instead of %dx%d or %dx%d", ErrInvalidDimension, size.X, size.Y, IconBounds.Dx()/2, IconBounds.Dy()/2, IconBounds.Dx(), IconBounds.Dy())
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package assets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"path"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Directories of a resource pack's namespaces containing textures imported as icons.
+const (
+	resourcePackBlocks = "textures/block"
+	resourcePackItems  = "textures/item"
+)
+
+// textureMeta is the content of a texture's .mcmeta file.
+type textureMeta struct {
+	Animation *struct {
+		Width  int               `json:"width"`
+		Height int               `json:"height"`
+		Frames []json.RawMessage `json:"frames"`
+	} `json:"animation"`
+}
+
+// LoadResourcePack imports the item and block textures of the Minecraft resource pack at the given path, which is either a directory or a zip file.
+// It returns a pack of icons for each namespace of the resource pack, e.g. "minecraft:diamond_sword".
+func LoadResourcePack(path string) ([]Pack, error) {
+	fsys, closer, err := openPack(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	packs, err := ReadResourcePack(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("loading resource pack %s: %w", path, err)
+	}
+	for i := range packs {
+		packs[i].Path = path
+	}
+	return packs, nil
+}
+
+// ReadResourcePack imports the item and block textures of a Minecraft resource pack from the given file system.
+// Textures that cannot be used as icons, e.g. because of their names or dimensions, are skipped.
+// Animated textures are imported using their first frame; all textures are scaled to 16x16 or 32x32 pixels.
+//
+// Blocks with textures for their top and side ("tnt_top" and "tnt_side", optionally "tnt_front") are declared as isometric blocks.
+// Items take precedence over blocks of the same name.
+func ReadResourcePack(fsys fs.FS) ([]Pack, error) {
+	fsys, err := packRoot(fsys, "assets")
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := fs.ReadDir(fsys, "assets")
+	if err != nil {
+		return nil, fmt.Errorf("reading assets: %w", err)
+	}
+
+	var packs []Pack
+	for _, namespace := range namespaces {
+		if !namespace.IsDir() {
+			continue
+		}
+		if !namePattern.MatchString(namespace.Name()) {
+			slog.Warn("skipping namespace of resource pack", "namespace", namespace.Name(), "error", ErrInvalidName)
+			continue
+		}
+
+		pack := Pack{
+			Namespace: namespace.Name(),
+			Icons:     make(map[string]image.Image),
+			Blocks:    make(map[string]Block),
+		}
+		blocks := readTextures(fsys, path.Join("assets", namespace.Name(), resourcePackBlocks))
+		items := readTextures(fsys, path.Join("assets", namespace.Name(), resourcePackItems))
+		maps.Copy(pack.Icons, blocks)
+		maps.Copy(pack.Icons, items)
+
+		for texture := range blocks {
+			name, found := strings.CutSuffix(texture, "_top")
+			if _, exists := blocks[name+"_side"]; !found || !exists {
+				continue
+			}
+			if _, exists := items[name]; exists {
+				continue
+			}
+			block := Block{Top: pack.Name(texture), Side: pack.Name(name + "_side")}
+			if _, exists := blocks[name+"_front"]; exists {
+				block.Front = pack.Name(name + "_front")
+			}
+			pack.Blocks[name] = block
+			delete(pack.Icons, name)
+		}
+
+		if len(pack.Icons) > 0 {
+			packs = append(packs, pack)
+		}
+	}
+	if len(packs) == 0 {
+		return nil, errors.New("no item or block textures found")
+	}
+	return packs, nil
+}
+
+// readTextures imports all textures of the given directory as icons.
+func readTextures(fsys fs.FS, dir string) map[string]image.Image {
+	icons := make(map[string]image.Image)
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("skipping textures of resource pack", "directory", dir, "error", err)
+		}
+		return icons
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".png" {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".png")
+		file := path.Join(dir, entry.Name())
+		if !namePattern.MatchString(name) {
+			slog.Debug("skipping texture of resource pack", "file", file, "error", ErrInvalidName)
+			continue
+		}
+
+		icon, err := readTexture(fsys, file)
+		if err != nil {
+			slog.Debug("skipping texture of resource pack", "file", file, "error", err)
+			continue
+		}
+		icons[name] = icon
+	}
+	return icons
+}
+
+// readTexture decodes a texture, crops it to its first frame if it is animated and scales it to the size of an icon.
+func readTexture(fsys fs.FS, file string) (image.Image, error) {
+	texture, err := decodeImage(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta textureMeta
+	content, err := fs.ReadFile(fsys, file+".mcmeta")
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(content, &meta); err != nil {
+			return nil, fmt.Errorf("decoding %s.mcmeta: %w", file, err)
+		}
+	}
+	if meta.Animation != nil {
+		texture, err = firstFrame(texture, meta)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	size := texture.Bounds().Size()
+	if size.X != size.Y || size.X == 0 {
+		return nil, fmt.Errorf("%w: %dx%d is not square", ErrInvalidDimension, size.X, size.Y)
+	}
+	if CheckIcon(texture) == nil {
+		return texture, nil
+	}
+
+	// scale textures of other resolutions, keeping the pixels of low resolution textures sharp
+	var scaler xdraw.Scaler = xdraw.NearestNeighbor
+	if size.X > IconBounds.Dx() {
+		scaler = xdraw.CatmullRom
+	}
+	icon := image.NewNRGBA(image.Rect(0, 0, IconBounds.Dx(), IconBounds.Dy()))
+	scaler.Scale(icon, icon.Bounds(), texture, texture.Bounds(), xdraw.Src, nil)
+	return icon, nil
+}
+
+// firstFrame returns the first frame of an animated texture.
+// Like the game, frames are squares of the texture's smaller side unless their size is given, and are laid out from left to right and top to bottom.
+func firstFrame(texture image.Image, meta textureMeta) (image.Image, error) {
+	bounds := texture.Bounds()
+	width, height := meta.Animation.Width, meta.Animation.Height
+	if width <= 0 && height <= 0 {
+		width = min(bounds.Dx(), bounds.Dy())
+		height = width
+	}
+	if width <= 0 {
+		width = bounds.Dx()
+	}
+	if height <= 0 {
+		height = bounds.Dy()
+	}
+	if width > bounds.Dx() || height > bounds.Dy() {
+		return nil, fmt.Errorf("%w: frames of %dx%d exceed texture of %dx%d", ErrInvalidDimension, width, height, bounds.Dx(), bounds.Dy())
+	}
+
+	// frames are listed either as index or as object with index and time
+	index := 0
+	if len(meta.Animation.Frames) > 0 {
+		var frame struct {
+			Index int `json:"index"`
+		}
+		if err := json.Unmarshal(meta.Animation.Frames[0], &index); err != nil {
+			if err := json.Unmarshal(meta.Animation.Frames[0], &frame); err != nil {
+				return nil, fmt.Errorf("decoding animation frames: %w", err)
+			}
+			index = frame.Index
+		}
+	}
+
+	columns := bounds.Dx() / width
+	if index < 0 || index >= columns*(bounds.Dy()/height) {
+		return nil, fmt.Errorf("animation frame %d does not exist", index)
+	}
+	origin := bounds.Min.Add(image.Pt(index%columns*width, index/columns*height))
+
+	frame := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.Copy(frame, image.Point{}, texture, image.Rectangle{Min: origin, Max: origin.Add(image.Pt(width, height))}, xdraw.Src, nil)
+	return frame, nil
+}
@@ -0,0 +1,28 @@
+package assets
+
+// A TintLayer selects the pixels of an icon that are recoloured by a tint, like the game tints potions and spawn eggs.
+// Pixels are selected either by their hue (in degrees, wrapping around if MinHue is greater than MaxHue) and saturation, or by their brightness.
+type TintLayer struct {
+	Name string
+
+	MinHue        float64
+	MaxHue        float64
+	MinSaturation float64
+
+	// MaxValue selects all pixels at most this bright (between 0 and 1) instead of matching their hue.
+	MaxValue float64
+}
+
+// TintLayers lists the layers of all icons that can be tinted, in the order tints are applied to them.
+var TintLayers = map[string][]TintLayer{
+	"potion": {
+		{Name: "liquid", MinHue: 200, MaxHue: 250, MinSaturation: 0.4},
+	},
+	"splash_potion": {
+		{Name: "liquid", MinHue: 330, MaxHue: 40, MinSaturation: 0.25},
+	},
+	"spawn_egg": {
+		{Name: "base", MinHue: 90, MaxHue: 150, MinSaturation: 0.5},
+		{Name: "spots", MaxValue: 0.05},
+	},
+}
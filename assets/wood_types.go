@@ -0,0 +1,27 @@
+package assets
+
+import "image/color"
+
+// A WoodType holds the colours signs of a wood are drawn in.
+type WoodType struct {
+	// Planks is the average colour of the planks the sign's board is made of.
+	Planks color.RGBA
+	// Log is the colour of the stripped log used for the post of signs and the board of hanging signs.
+	Log color.RGBA
+}
+
+// WoodTypes maps the IDs of the game's wood types to their colours.
+var WoodTypes = map[string]WoodType{
+	"oak":      {Planks: color.RGBA{R: 162, G: 131, B: 79, A: 255}, Log: color.RGBA{R: 177, G: 144, B: 86, A: 255}},
+	"spruce":   {Planks: color.RGBA{R: 115, G: 85, B: 49, A: 255}, Log: color.RGBA{R: 116, G: 90, B: 52, A: 255}},
+	"birch":    {Planks: color.RGBA{R: 192, G: 175, B: 121, A: 255}, Log: color.RGBA{R: 196, G: 176, B: 118, A: 255}},
+	"jungle":   {Planks: color.RGBA{R: 160, G: 115, B: 81, A: 255}, Log: color.RGBA{R: 171, G: 132, B: 84, A: 255}},
+	"acacia":   {Planks: color.RGBA{R: 168, G: 90, B: 50, A: 255}, Log: color.RGBA{R: 174, G: 92, B: 59, A: 255}},
+	"dark_oak": {Planks: color.RGBA{R: 67, G: 43, B: 20, A: 255}, Log: color.RGBA{R: 72, G: 56, B: 36, A: 255}},
+	"mangrove": {Planks: color.RGBA{R: 117, G: 54, B: 49, A: 255}, Log: color.RGBA{R: 119, G: 54, B: 47, A: 255}},
+	"cherry":   {Planks: color.RGBA{R: 226, G: 178, B: 172, A: 255}, Log: color.RGBA{R: 215, G: 145, B: 148, A: 255}},
+	"pale_oak": {Planks: color.RGBA{R: 227, G: 217, B: 214, A: 255}, Log: color.RGBA{R: 245, G: 238, B: 236, A: 255}},
+	"bamboo":   {Planks: color.RGBA{R: 194, G: 173, B: 80, A: 255}, Log: color.RGBA{R: 178, G: 158, B: 73, A: 255}},
+	"crimson":  {Planks: color.RGBA{R: 101, G: 49, B: 71, A: 255}, Log: color.RGBA{R: 137, G: 57, B: 90, A: 255}},
+	"warped":   {Planks: color.RGBA{R: 43, G: 105, B: 99, A: 255}, Log: color.RGBA{R: 58, G: 151, B: 148, A: 255}},
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/menzerath/mcgen/generator"
+)
+
+// renderChat renders the chat lines read from a file or the standard input into an image, one message per line.
+// Lines starting like JSON are parsed as text components, all others may contain formatting codes.
+// It returns the exit code of the command.
+func renderChat(arguments []string) int {
+	flags := flag.NewFlagSet("mcgen render-chat", flag.ContinueOnError)
+	output := flags.String("out", "chat.png", "file to write the image to")
+	width := flags.Int("width", generator.DefaultChatWidth, "width of the chat in pixels of the game's GUI")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: mcgen render-chat [-out file] [-width pixels] [file]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(arguments); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	input := io.Reader(os.Stdin)
+	if flags.NArg() == 1 && flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var lines []generator.ChatLine
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			lines = append(lines, generator.ChatLine{Component: json.RawMessage(trimmed)})
+			continue
+		}
+		lines = append(lines, generator.ChatLine{Text: text})
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	gen, err := generator.New(generator.DefaultOptions())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	image, err := gen.GenerateChat(context.Background(), lines, *width)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := os.WriteFile(*output, image, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("rendered %d chat lines to %s\n", len(lines), *output)
+	return 0
+}
@@ -0,0 +1,506 @@
+// Package config loads mcgen's configuration from a file, environment variables and command-line flags.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"log/slog"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/menzerath/mcgen/assets"
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/skins"
+	"github.com/menzerath/mcgen/web"
+)
+
+// ErrUnsupportedFormat is returned for configuration and settings files in formats other than JSON, e.g. YAML or TOML.
+var ErrUnsupportedFormat = errors.New("unsupported file format, only JSON is supported")
+
+// Config contains all settings of mcgen.
+type Config struct {
+	// Mode selects the defaults for logging; "production" logs JSON at info level.
+	Mode string `json:"mode"`
+
+	// SettingsFile is the path of a file with settings reloaded on SIGHUP (see Settings).
+	SettingsFile string `json:"settings_file"`
+
+	Log        Log        `json:"log"`
+	HTTP       HTTP       `json:"http"`
+	Metrics    Metrics    `json:"metrics"`
+	Generator  Generator  `json:"generator"`
+	Assets     Assets     `json:"assets"`
+	Features   Features   `json:"features"`
+	Links      Links      `json:"links"`
+	Skins      Skins      `json:"skins"`
+	Signing    Signing    `json:"signing"`
+	RateLimits RateLimits `json:"rate_limits"`
+	APIKeys    APIKeys    `json:"api_keys"`
+	Filter     Filter     `json:"filter"`
+}
+
+// Log configures logging.
+// Empty values are derived from the mode.
+type Log struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+// HTTP configures the HTTP server of the web API.
+type HTTP struct {
+	Listen            string   `json:"listen"`
+	ReadTimeout       Duration `json:"read_timeout"`
+	ReadHeaderTimeout Duration `json:"read_header_timeout"`
+	WriteTimeout      Duration `json:"write_timeout"`
+	IdleTimeout       Duration `json:"idle_timeout"`
+	ShutdownTimeout   Duration `json:"shutdown_timeout"`
+}
+
+// Metrics configures the HTTP server exposing Prometheus metrics.
+type Metrics struct {
+	Enabled bool   `json:"enabled"`
+	Listen  string `json:"listen"`
+}
+
+// Generator configures the defaults of generated images and how many are generated at once.
+type Generator struct {
+	FontSize    float64 `json:"font_size"`
+	TitleColor  Color   `json:"title_color"`
+	TextColor   Color   `json:"text_color"`
+	TitleOffset Offset  `json:"title_offset"`
+	TextOffset  Offset  `json:"text_offset"`
+
+	MaxConcurrent int      `json:"max_concurrent"`
+	MaxQueued     int      `json:"max_queued"`
+	QueueTimeout  Duration `json:"queue_timeout"`
+}
+
+// Assets configures packs of additional assets merged with the embedded ones.
+// Packs are given as "path" or "namespace=path" of a directory or zip file; resource packs are given by their path.
+type Assets struct {
+	Packs         []string `json:"packs"`
+	ResourcePacks []string `json:"resource_packs"`
+	Override      bool     `json:"override"`
+}
+
+// Features toggles optional parts of the web API.
+type Features struct {
+	UI         bool `json:"ui"`
+	Collage    bool `json:"collage"`
+	Screenshot bool `json:"screenshot"`
+	Immutable  bool `json:"immutable"`
+	Heads      bool `json:"heads"`
+	Players    bool `json:"players"`
+	Banners    bool `json:"banners"`
+	Recipes    bool `json:"recipes"`
+	Tooltips   bool `json:"tooltips"`
+	Chat       bool `json:"chat"`
+	Signboards bool `json:"signboards"`
+}
+
+// Links configures short links, which are enabled by setting a file.
+type Links struct {
+	File string `json:"file"`
+}
+
+// Skins configures the services skins of players are looked up at; lookups are disabled if the session URL is empty.
+type Skins struct {
+	ProfileURL string   `json:"profile_url"`
+	SessionURL string   `json:"session_url"`
+	CacheTTL   Duration `json:"cache_ttl"`
+	Timeout    Duration `json:"timeout"`
+}
+
+// Signing configures signed URLs, which are enabled by setting a secret.
+type Signing struct {
+	Secret         string   `json:"secret"`
+	Token          string   `json:"token"`
+	TrustOrigins   bool     `json:"trust_origins"`
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+// RateLimits configures the rate limits per client.
+type RateLimits struct {
+	Render         web.RateLimit `json:"render"`
+	Static         web.RateLimit `json:"static"`
+	TrustedProxies []Network     `json:"trusted_proxies"`
+}
+
+// APIKeys configures authentication by API keys.
+// Keys given in the file are added to the ones given in the configuration.
+type APIKeys struct {
+	Keys       []web.APIKey `json:"keys"`
+	File       string       `json:"file"`
+	Anonymous  bool         `json:"anonymous"`
+	AdminToken string       `json:"admin_token"`
+}
+
+// Filter configures the content filter, which is enabled by setting a file.
+type Filter struct {
+	File string `json:"file"`
+}
+
+// Default returns the default configuration.
+func Default() Config {
+	defaults := generator.DefaultOptions()
+	return Config{
+		HTTP: HTTP{
+			Listen:            ":8080",
+			ReadHeaderTimeout: Duration(10 * time.Second),
+			ReadTimeout:       Duration(60 * time.Second),
+			WriteTimeout:      Duration(60 * time.Second),
+			IdleTimeout:       Duration(120 * time.Second),
+			ShutdownTimeout:   Duration(5 * time.Second),
+		},
+		Metrics: Metrics{
+			Enabled: true,
+			Listen:  ":9100",
+		},
+		Generator: Generator{
+			FontSize:      defaults.FontSize,
+			TitleColor:    Color(color.RGBAModel.Convert(defaults.TitleColor).(color.RGBA)),
+			TextColor:     Color(color.RGBAModel.Convert(defaults.TextColor).(color.RGBA)),
+			TitleOffset:   Offset(defaults.TitleOffset),
+			TextOffset:    Offset(defaults.TextOffset),
+			MaxConcurrent: runtime.NumCPU(),
+			MaxQueued:     100,
+			QueueTimeout:  Duration(10 * time.Second),
+		},
+		Features: Features{
+			UI:         true,
+			Collage:    true,
+			Screenshot: true,
+			Immutable:  true,
+			Heads:      true,
+			Players:    true,
+			Banners:    true,
+			Recipes:    true,
+			Tooltips:   true,
+			Chat:       true,
+			Signboards: true,
+		},
+		Skins: Skins{
+			ProfileURL: "https://api.mojang.com/users/profiles/minecraft/",
+			SessionURL: "https://sessionserver.mojang.com/session/minecraft/profile/",
+			CacheTTL:   Duration(time.Hour),
+			Timeout:    Duration(5 * time.Second),
+		},
+		APIKeys: APIKeys{
+			Anonymous: true,
+		},
+	}
+}
+
+// Load builds the configuration from the given command-line arguments and the environment.
+// Flags take precedence over environment variables, which take precedence over the configuration file, which takes precedence over the defaults.
+// The configuration file is given by the `-config` flag or the CONFIG_FILE environment variable.
+// It returns flag.ErrHelp if the usage was requested and printed to output.
+func Load(arguments []string, output io.Writer) (Config, bool, error) {
+	config := Default()
+	settings := config.settings()
+
+	flags := flag.NewFlagSet("mcgen", flag.ContinueOnError)
+	flags.SetOutput(output)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "path to a JSON configuration file (env CONFIG_FILE)")
+	printConfig := flags.Bool("print-config", false, "print the effective configuration and exit")
+
+	// flags are parsed into the configuration and recorded, as they are applied again after the file and the environment
+	flagValues := make(map[string]*string)
+	for _, setting := range settings {
+		flagValues[setting.flag] = new(string)
+		flags.Var(recordedValue{Value: setting.value, record: flagValues[setting.flag]}, setting.flag, fmt.Sprintf("%s (env %s)", setting.usage, strings.Join(setting.env, ", ")))
+	}
+	if err := flags.Parse(arguments); err != nil {
+		return Config{}, false, err
+	}
+	setFlags := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
+	// start from the defaults again, as parsing the flags modified the configuration already
+	config = Default()
+	settings = config.settings()
+	if *configFile != "" {
+		if err := config.loadFile(*configFile); err != nil {
+			return Config{}, false, err
+		}
+	}
+
+	for _, setting := range settings {
+		for _, name := range setting.env {
+			if value, exists := os.LookupEnv(name); exists {
+				if err := setting.value.Set(value); err != nil {
+					return Config{}, false, fmt.Errorf("invalid %s %q: %w", name, value, err)
+				}
+			}
+		}
+		if setFlags[setting.flag] {
+			if err := setting.value.Set(*flagValues[setting.flag]); err != nil {
+				return Config{}, false, fmt.Errorf("invalid -%s: %w", setting.flag, err)
+			}
+		}
+	}
+
+	config.applyDerivedDefaults()
+	if err := config.Validate(); err != nil {
+		return Config{}, false, err
+	}
+	return config, *printConfig, nil
+}
+
+// loadFile reads the given JSON configuration file on top of the current configuration.
+func (config *Config) loadFile(path string) error {
+	if err := checkFormat(path); err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading configuration file: %w", err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(content)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("decoding configuration file %s: %w", path, err)
+	}
+	return nil
+}
+
+// checkFormat rejects files that are named like YAML or TOML files, which would otherwise fail with confusing JSON errors.
+// Files with other extensions are read as JSON.
+func checkFormat(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".toml":
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, path)
+	}
+	return nil
+}
+
+// applyDerivedDefaults fills all empty values that depend on other values.
+func (config *Config) applyDerivedDefaults() {
+	if config.Log.Level == "" {
+		config.Log.Level = "debug"
+		if config.Mode == "production" {
+			config.Log.Level = "info"
+		}
+	}
+	if config.Log.Format == "" {
+		config.Log.Format = "text"
+		if config.Mode == "production" {
+			config.Log.Format = "json"
+		}
+	}
+
+	// a rate limit without burst allows as many requests at once as per second
+	for _, limit := range []*web.RateLimit{&config.RateLimits.Render, &config.RateLimits.Static} {
+		if limit.Burst == 0 {
+			limit.Burst = max(1, int(math.Ceil(limit.Rate)))
+		}
+	}
+}
+
+// Validate checks all settings and returns all problems found.
+func (config Config) Validate() error {
+	var problems []error
+	check := func(valid bool, format string, arguments ...any) {
+		if !valid {
+			problems = append(problems, fmt.Errorf(format, arguments...))
+		}
+	}
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(config.Log.Level)) == nil, "log.level: unknown level %q", config.Log.Level)
+	check(slices.Contains([]string{"text", "json"}, config.Log.Format), "log.format: must be text or json, not %q", config.Log.Format)
+
+	check(config.HTTP.Listen != "", "http.listen: must not be empty")
+	for name, duration := range map[string]Duration{
+		"http.read_timeout":        config.HTTP.ReadTimeout,
+		"http.read_header_timeout": config.HTTP.ReadHeaderTimeout,
+		"http.write_timeout":       config.HTTP.WriteTimeout,
+		"http.idle_timeout":        config.HTTP.IdleTimeout,
+		"http.shutdown_timeout":    config.HTTP.ShutdownTimeout,
+		"generator.queue_timeout":  config.Generator.QueueTimeout,
+		"skins.cache_ttl":          config.Skins.CacheTTL,
+		"skins.timeout":            config.Skins.Timeout,
+	} {
+		check(duration >= 0, "%s: must not be negative", name)
+	}
+	check(!config.Metrics.Enabled || config.Metrics.Listen != "", "metrics.listen: must not be empty")
+	check(!config.Metrics.Enabled || config.Metrics.Listen != config.HTTP.Listen, "metrics.listen: must differ from http.listen")
+
+	check(config.Generator.FontSize > 0 && config.Generator.FontSize <= 64, "generator.font_size: must be between 0 and 64")
+	check(config.Generator.MaxConcurrent >= 0, "generator.max_concurrent: must not be negative")
+	check(config.Generator.MaxQueued >= 0, "generator.max_queued: must not be negative")
+
+	for _, spec := range config.Assets.Packs {
+		_, _, err := assets.ParsePackSpec(spec)
+		check(err == nil, "assets.packs: %v", err)
+	}
+
+	check(config.Skins.SessionURL == "" || config.Skins.ProfileURL != "", "skins.profile_url: must not be empty")
+
+	check(config.Signing.Token == "" || config.Signing.Secret != "", "signing.token: requires signing.secret")
+	check(len(config.Signing.AllowedOrigins) == 0 || config.Signing.TrustOrigins, "signing.allowed_origins: requires signing.trust_origins")
+	for name, limit := range map[string]web.RateLimit{"rate_limits.render": config.RateLimits.Render, "rate_limits.static": config.RateLimits.Static} {
+		check(limit.Rate >= 0 && limit.Burst >= 0, "%s: must not be negative", name)
+	}
+	if err := web.ValidateAPIKeys(config.APIKeys.Keys); err != nil {
+		problems = append(problems, fmt.Errorf("api_keys.keys: %w", err))
+	}
+
+	return errors.Join(problems...)
+}
+
+// LogLevel returns the configured log level.
+func (config Config) LogLevel() slog.Level {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(config.Log.Level))
+	return level
+}
+
+// LogHandler returns the slog handler configured for logging to the given writer at the given level.
+func (config Config) LogHandler(output io.Writer, level slog.Leveler) slog.Handler {
+	if config.Log.Format == "json" {
+		return slog.NewJSONHandler(output, &slog.HandlerOptions{
+			Level: level,
+		})
+	}
+	return slog.NewTextHandler(output, &slog.HandlerOptions{
+		AddSource: true,
+		Level:     level,
+	})
+}
+
+// GeneratorOptions returns the options for the generator.
+func (config Config) GeneratorOptions() generator.Options {
+	return generator.Options{
+		FontSize:       config.Generator.FontSize,
+		TitleColor:     config.Generator.TitleColor.RGBA(),
+		TextColor:      config.Generator.TextColor.RGBA(),
+		TitleOffset:    image.Point(config.Generator.TitleOffset),
+		TextOffset:     image.Point(config.Generator.TextOffset),
+		OverrideAssets: config.Assets.Override,
+		Concurrency: generator.ConcurrencyLimits{
+			MaxConcurrent: config.Generator.MaxConcurrent,
+			MaxQueued:     config.Generator.MaxQueued,
+			QueueTimeout:  time.Duration(config.Generator.QueueTimeout),
+		},
+	}
+}
+
+// LoadPacks loads all configured resource packs, followed by the asset packs.
+func (config Assets) LoadPacks() ([]assets.Pack, error) {
+	var packs []assets.Pack
+	for _, path := range config.ResourcePacks {
+		resourcePacks, err := assets.LoadResourcePack(path)
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, resourcePacks...)
+	}
+	for _, spec := range config.Packs {
+		namespace, path, err := assets.ParsePackSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		pack, err := assets.LoadPack(path, namespace)
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+	return packs, nil
+}
+
+// Server returns the settings of the web API's HTTP server.
+func (config HTTP) Server() web.Server {
+	return web.Server{
+		Address:           config.Listen,
+		ReadTimeout:       time.Duration(config.ReadTimeout),
+		ReadHeaderTimeout: time.Duration(config.ReadHeaderTimeout),
+		WriteTimeout:      time.Duration(config.WriteTimeout),
+		IdleTimeout:       time.Duration(config.IdleTimeout),
+		ShutdownTimeout:   time.Duration(config.ShutdownTimeout),
+	}
+}
+
+// ConfigureWebAPI applies the configuration to the given web API.
+// Links, API keys from files and the content filter have to be loaded separately.
+func (config Config) ConfigureWebAPI(webAPI *web.WebAPI) {
+	webAPI.Server = config.HTTP.Server()
+	webAPI.Features = web.Features{
+		UI:         config.Features.UI,
+		Collage:    config.Features.Collage,
+		Screenshot: config.Features.Screenshot,
+		Immutable:  config.Features.Immutable,
+		Heads:      config.Features.Heads,
+		Players:    config.Features.Players,
+		Banners:    config.Features.Banners,
+		Recipes:    config.Features.Recipes,
+		Tooltips:   config.Features.Tooltips,
+		Chat:       config.Features.Chat,
+		Signboards: config.Features.Signboards,
+	}
+	if config.Skins.SessionURL != "" {
+		webAPI.Skins = skins.NewService(skins.Options{
+			ProfileURL: config.Skins.ProfileURL,
+			SessionURL: config.Skins.SessionURL,
+			CacheTTL:   time.Duration(config.Skins.CacheTTL),
+			Timeout:    time.Duration(config.Skins.Timeout),
+		})
+	}
+	webAPI.Signing = web.Signing{
+		Secret:         []byte(config.Signing.Secret),
+		Token:          config.Signing.Token,
+		TrustOrigins:   config.Signing.TrustOrigins,
+		AllowedOrigins: config.Signing.AllowedOrigins,
+	}
+	webAPI.RateLimits = web.RateLimits{
+		Render: config.RateLimits.Render,
+		Static: config.RateLimits.Static,
+	}
+	for _, network := range config.RateLimits.TrustedProxies {
+		webAPI.RateLimits.TrustedProxies = append(webAPI.RateLimits.TrustedProxies, netip.Prefix(network))
+	}
+	webAPI.APIKeys = web.APIKeys{
+		Keys:       slices.Clone(config.APIKeys.Keys),
+		Anonymous:  config.APIKeys.Anonymous,
+		AdminToken: config.APIKeys.AdminToken,
+	}
+}
+
+// redacted replaces secrets when printing the configuration.
+const redacted = "<redacted>"
+
+// Print writes the configuration as JSON to the given writer, with all secrets redacted.
+func (config Config) Print(output io.Writer) error {
+	redact := func(secret *string) {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+	redact(&config.Signing.Secret)
+	redact(&config.Signing.Token)
+	redact(&config.APIKeys.AdminToken)
+	config.APIKeys.Keys = slices.Clone(config.APIKeys.Keys)
+	for i := range config.APIKeys.Keys {
+		redact(&config.APIKeys.Keys[i].Key)
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(config)
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFileFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr error
+	}{
+		{"json", "mcgen.json", nil},
+		{"no extension", "mcgen", nil},
+		{"yaml", "mcgen.yaml", ErrUnsupportedFormat},
+		{"yml", "mcgen.YML", ErrUnsupportedFormat},
+		{"toml", "mcgen.toml", ErrUnsupportedFormat},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.file)
+			if err := os.WriteFile(path, []byte(`{"http": {"listen": ":8081"}}`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			config, _, err := Load([]string{"-config", path}, io.Discard)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Load() error = %v, want %v", err, test.wantErr)
+			}
+			if test.wantErr == nil && config.HTTP.Listen != ":8081" {
+				t.Errorf("Load() http.listen = %q, want %q", config.HTTP.Listen, ":8081")
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"flag"
+	"strings"
+)
+
+// A setting can be set by a command-line flag and environment variables.
+type setting struct {
+	flag  string
+	env   []string
+	usage string
+	value flag.Value
+}
+
+// recordedValue is a flag.Value recording the last text it was set to.
+type recordedValue struct {
+	flag.Value
+	record *string
+}
+
+func (value recordedValue) String() string {
+	if value.Value == nil {
+		return ""
+	}
+	return value.Value.String()
+}
+
+func (value recordedValue) Set(text string) error {
+	if err := value.Value.Set(text); err != nil {
+		return err
+	}
+	*value.record = text
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be given without a value.
+func (value recordedValue) IsBoolFlag() bool {
+	boolFlag, ok := value.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// portValue sets a listen address from a port number, as given by the PORT environment variable.
+type portValue struct {
+	target *string
+}
+
+func (value portValue) String() string {
+	if value.target == nil {
+		return ""
+	}
+	_, port, _ := strings.Cut(*value.target, ":")
+	return port
+}
+
+func (value portValue) Set(port string) error {
+	*value.target = ":" + port
+	return nil
+}
+
+// settings returns all settings bound to the configuration's fields.
+// If multiple environment variables are given for a setting, later ones take precedence.
+func (config *Config) settings() []setting {
+	return []setting{
+		{"mode", []string{"MODE"}, "mode selecting logging defaults, e.g. production", basicValue[string]{&config.Mode}},
+		{"settings_file", []string{"SETTINGS_FILE"}, "JSON file of settings reloaded on SIGHUP", basicValue[string]{&config.SettingsFile}},
+		{"log.level", []string{"LOG_LEVEL"}, "log level (debug, info, warn, error)", basicValue[string]{&config.Log.Level}},
+		{"log.format", []string{"LOG_FORMAT"}, "log format (text, json)", basicValue[string]{&config.Log.Format}},
+
+		{"http.port", []string{"PORT"}, "port of the web api, shorthand for -http.listen", portValue{&config.HTTP.Listen}},
+		{"http.listen", []string{"LISTEN_ADDRESS"}, "listen address of the web api", basicValue[string]{&config.HTTP.Listen}},
+		{"http.read_timeout", []string{"HTTP_READ_TIMEOUT"}, "maximum duration for reading a request", textValue[Duration, *Duration]{&config.HTTP.ReadTimeout}},
+		{"http.read_header_timeout", []string{"HTTP_READ_HEADER_TIMEOUT"}, "maximum duration for reading request headers", textValue[Duration, *Duration]{&config.HTTP.ReadHeaderTimeout}},
+		{"http.write_timeout", []string{"HTTP_WRITE_TIMEOUT"}, "maximum duration for writing a response", textValue[Duration, *Duration]{&config.HTTP.WriteTimeout}},
+		{"http.idle_timeout", []string{"HTTP_IDLE_TIMEOUT"}, "maximum duration to keep idle connections open", textValue[Duration, *Duration]{&config.HTTP.IdleTimeout}},
+		{"http.shutdown_timeout", []string{"HTTP_SHUTDOWN_TIMEOUT"}, "maximum duration to wait for in-flight requests when shutting down", textValue[Duration, *Duration]{&config.HTTP.ShutdownTimeout}},
+
+		{"metrics.enabled", []string{"METRICS_ENABLED"}, "expose prometheus metrics", basicValue[bool]{&config.Metrics.Enabled}},
+		{"metrics.listen", []string{"METRICS_LISTEN_ADDRESS"}, "listen address of the metrics server", basicValue[string]{&config.Metrics.Listen}},
+
+		{"generator.font_size", []string{"FONT_SIZE"}, "font size of title and text", basicValue[float64]{&config.Generator.FontSize}},
+		{"generator.title_color", []string{"TITLE_COLOR"}, "color of the title", textValue[Color, *Color]{&config.Generator.TitleColor}},
+		{"generator.text_color", []string{"TEXT_COLOR"}, "color of the text", textValue[Color, *Color]{&config.Generator.TextColor}},
+		{"generator.title_offset", []string{"TITLE_OFFSET"}, "position of the title's baseline as x,y", textValue[Offset, *Offset]{&config.Generator.TitleOffset}},
+		{"generator.text_offset", []string{"TEXT_OFFSET"}, "position of the text's baseline as x,y", textValue[Offset, *Offset]{&config.Generator.TextOffset}},
+		{"generator.max_concurrent", []string{"MAX_CONCURRENT_RENDERS"}, "maximum number of images generated at once, 0 is unlimited", basicValue[int]{&config.Generator.MaxConcurrent}},
+		{"generator.max_queued", []string{"MAX_QUEUED_RENDERS"}, "maximum number of requests waiting for a free slot", basicValue[int]{&config.Generator.MaxQueued}},
+		{"generator.queue_timeout", []string{"RENDER_QUEUE_TIMEOUT"}, "maximum duration to wait for a free slot", textValue[Duration, *Duration]{&config.Generator.QueueTimeout}},
+
+		{"assets.packs", []string{"ASSET_PACKS"}, "comma-separated directories or zip files of additional assets, optionally as namespace=path", stringsValue{&config.Assets.Packs}},
+		{"assets.resource_packs", []string{"RESOURCE_PACKS"}, "comma-separated directories or zip files of minecraft resource packs whose item and block textures are added as icons", stringsValue{&config.Assets.ResourcePacks}},
+		{"assets.override", []string{"ASSETS_OVERRIDE"}, "allow asset packs to replace existing assets", basicValue[bool]{&config.Assets.Override}},
+
+		{"features.ui", []string{"FEATURE_UI"}, "serve the ui", basicValue[bool]{&config.Features.UI}},
+		{"features.collage", []string{"FEATURE_COLLAGE"}, "enable the collage api", basicValue[bool]{&config.Features.Collage}},
+		{"features.screenshot", []string{"FEATURE_SCREENSHOT"}, "enable the screenshot api", basicValue[bool]{&config.Features.Screenshot}},
+		{"features.immutable", []string{"FEATURE_IMMUTABLE"}, "enable immutable urls", basicValue[bool]{&config.Features.Immutable}},
+		{"features.heads", []string{"FEATURE_HEADS"}, "enable the api rendering heads of uploaded skins", basicValue[bool]{&config.Features.Heads}},
+		{"features.players", []string{"FEATURE_PLAYERS"}, "enable the api rendering full bodies of players", basicValue[bool]{&config.Features.Players}},
+		{"features.banners", []string{"FEATURE_BANNERS"}, "enable the api rendering banners", basicValue[bool]{&config.Features.Banners}},
+		{"features.recipes", []string{"FEATURE_RECIPES"}, "enable the api rendering crafting recipes", basicValue[bool]{&config.Features.Recipes}},
+		{"features.tooltips", []string{"FEATURE_TOOLTIPS"}, "enable the api rendering item tooltips", basicValue[bool]{&config.Features.Tooltips}},
+		{"features.chat", []string{"FEATURE_CHAT"}, "enable the api rendering chat messages", basicValue[bool]{&config.Features.Chat}},
+		{"features.signboards", []string{"FEATURE_SIGNBOARDS"}, "enable the api rendering signs of all wood types", basicValue[bool]{&config.Features.Signboards}},
+
+		{"links.file", []string{"LINKS_FILE"}, "file storing short links, enables short links", basicValue[string]{&config.Links.File}},
+
+		{"skins.profile_url", []string{"SKINS_PROFILE_URL"}, "base url returning the profile of a player by name", basicValue[string]{&config.Skins.ProfileURL}},
+		{"skins.session_url", []string{"SKINS_SESSION_URL"}, "base url returning the textures of a player by uuid, empty disables heads of players", basicValue[string]{&config.Skins.SessionURL}},
+		{"skins.cache_ttl", []string{"SKINS_CACHE_TTL"}, "how long looked up skins are cached", textValue[Duration, *Duration]{&config.Skins.CacheTTL}},
+		{"skins.timeout", []string{"SKINS_TIMEOUT"}, "maximum duration of looking up a skin", textValue[Duration, *Duration]{&config.Skins.Timeout}},
+
+		{"signing.secret", []string{"SIGNING_SECRET"}, "secret for signed urls, enables hotlink protection", basicValue[string]{&config.Signing.Secret}},
+		{"signing.token", []string{"SIGNING_TOKEN"}, "token of trusted callers allowed to sign urls", basicValue[string]{&config.Signing.Token}},
+		{"signing.trust_origins", []string{"SIGNING_TRUST_ORIGINS"}, "allow the same host and allowed origins without signature, by their forgeable Origin and Referer headers", basicValue[bool]{&config.Signing.TrustOrigins}},
+		{"signing.allowed_origins", []string{"ALLOWED_ORIGINS"}, "comma-separated origins allowed without signature if origins are trusted", stringsValue{&config.Signing.AllowedOrigins}},
+
+		{"rate_limits.render.rate", []string{"RATE_LIMIT_RENDER"}, "requests per second per client for api routes, 0 is unlimited", basicValue[float64]{&config.RateLimits.Render.Rate}},
+		{"rate_limits.render.burst", []string{"RATE_LIMIT_RENDER_BURST"}, "burst size for api routes", basicValue[int]{&config.RateLimits.Render.Burst}},
+		{"rate_limits.static.rate", []string{"RATE_LIMIT_STATIC"}, "requests per second per client for static files, 0 is unlimited", basicValue[float64]{&config.RateLimits.Static.Rate}},
+		{"rate_limits.static.burst", []string{"RATE_LIMIT_STATIC_BURST"}, "burst size for static files", basicValue[int]{&config.RateLimits.Static.Burst}},
+		{"rate_limits.trusted_proxies", []string{"TRUSTED_PROXIES"}, "comma-separated ips or networks of trusted reverse proxies", listValue[Network, *Network]{&config.RateLimits.TrustedProxies}},
+
+		{"api_keys.file", []string{"API_KEYS_FILE"}, "JSON file of api keys", basicValue[string]{&config.APIKeys.File}},
+		{"api_keys.anonymous", []string{"ALLOW_ANONYMOUS"}, "allow requests without api key", basicValue[bool]{&config.APIKeys.Anonymous}},
+		{"api_keys.admin_token", []string{"ADMIN_TOKEN"}, "token granting access to the usage of all api keys", basicValue[string]{&config.APIKeys.AdminToken}},
+
+		{"filter.file", []string{"FILTER_FILE"}, "JSON file of content filter rules", basicValue[string]{&config.Filter.File}},
+	}
+}
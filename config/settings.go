@@ -0,0 +1,71 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Settings contains the values of the settings file, which are reloaded at runtime.
+// Values missing in the file fall back to the ones of the configuration.
+type Settings struct {
+	Log struct {
+		Level string `json:"level"`
+	} `json:"log"`
+	HTTP      HTTP `json:"http"`
+	Generator struct {
+		TitleColor Color `json:"title_color"`
+		TextColor  Color `json:"text_color"`
+	} `json:"generator"`
+
+	// LegacyIcons adds to or replaces the built-in mappings of legacy icon IDs to backgrounds.
+	LegacyIcons map[string]string `json:"legacy_icons"`
+
+	// ItemIcons adds to or replaces the built-in mappings of item and block IDs to backgrounds.
+	ItemIcons map[string]string `json:"item_icons"`
+}
+
+// LoadSettings reads the settings file at the given path on top of the given configuration and validates the result.
+func LoadSettings(path string, config Config) (Settings, error) {
+	var settings Settings
+	settings.Log.Level = config.Log.Level
+	settings.HTTP = config.HTTP
+	settings.Generator.TitleColor = config.Generator.TitleColor
+	settings.Generator.TextColor = config.Generator.TextColor
+
+	if err := checkFormat(path); err != nil {
+		return Settings{}, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return Settings{}, fmt.Errorf("reading settings file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		return Settings{}, fmt.Errorf("decoding settings file %s: %w", path, err)
+	}
+
+	// validate the settings as part of the whole configuration
+	config.Log.Level = settings.Log.Level
+	config.HTTP = settings.HTTP
+	config.Generator.TitleColor = settings.Generator.TitleColor
+	config.Generator.TextColor = settings.Generator.TextColor
+	if err := config.Validate(); err != nil {
+		return Settings{}, fmt.Errorf("validating settings file %s: %w", path, err)
+	}
+	for id, background := range settings.LegacyIcons {
+		if id == "" || background == "" {
+			return Settings{}, fmt.Errorf("validating settings file %s: legacy icon mappings must not be empty", path)
+		}
+	}
+	for id, background := range settings.ItemIcons {
+		if id == "" || background == "" {
+			return Settings{}, fmt.Errorf("validating settings file %s: item icon mappings must not be empty", path)
+		}
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"image"
+	"image/color"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration written like "10s" in configuration files.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (duration Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(duration).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (duration *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*duration = Duration(parsed)
+	return nil
+}
+
+// Color is a color written like "#ffff00" or "#ffff0080" in configuration files.
+type Color color.RGBA
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Color) MarshalText() ([]byte, error) {
+	if c.A == 255 {
+		return []byte(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)), nil
+	}
+	return []byte(fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Color) UnmarshalText(text []byte) error {
+	hex := strings.TrimPrefix(string(text), "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 {
+		return fmt.Errorf("invalid color %q", text)
+	}
+
+	*c = Color{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}
+	return nil
+}
+
+// RGBA returns the color as color.RGBA.
+func (c Color) RGBA() color.RGBA {
+	return color.RGBA(c)
+}
+
+// Offset is a position written like "60,28" in configuration files.
+type Offset image.Point
+
+// MarshalText implements encoding.TextMarshaler.
+func (offset Offset) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", offset.X, offset.Y)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (offset *Offset) UnmarshalText(text []byte) error {
+	x, y, found := strings.Cut(string(text), ",")
+	if !found {
+		return fmt.Errorf("invalid offset %q", text)
+	}
+
+	parsedX, errX := strconv.Atoi(strings.TrimSpace(x))
+	parsedY, errY := strconv.Atoi(strings.TrimSpace(y))
+	if errX != nil || errY != nil {
+		return fmt.Errorf("invalid offset %q", text)
+	}
+
+	*offset = Offset{X: parsedX, Y: parsedY}
+	return nil
+}
+
+// Network is an IP network written like "10.0.0.0/8" in configuration files.
+// A single IP address is treated as a network containing only this address.
+type Network netip.Prefix
+
+// MarshalText implements encoding.TextMarshaler.
+func (network Network) MarshalText() ([]byte, error) {
+	return netip.Prefix(network).MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (network *Network) UnmarshalText(text []byte) error {
+	prefix, err := netip.ParsePrefix(string(text))
+	if err != nil {
+		address, addressErr := netip.ParseAddr(string(text))
+		if addressErr != nil {
+			return fmt.Errorf("invalid network %q", text)
+		}
+		prefix = netip.PrefixFrom(address, address.BitLen())
+	}
+
+	*network = Network(prefix)
+	return nil
+}
+
+// textValue is a flag.Value setting any value implementing encoding.TextUnmarshaler.
+type textValue[T any, P interface {
+	*T
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}] struct {
+	target P
+}
+
+func (value textValue[T, P]) String() string {
+	if value.target == nil {
+		return ""
+	}
+	text, _ := value.target.MarshalText()
+	return string(text)
+}
+
+func (value textValue[T, P]) Set(text string) error {
+	return value.target.UnmarshalText([]byte(text))
+}
+
+// listValue is a flag.Value setting a comma-separated list of values.
+type listValue[T any, P interface {
+	*T
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}] struct {
+	target *[]T
+}
+
+func (value listValue[T, P]) String() string {
+	if value.target == nil {
+		return ""
+	}
+
+	items := make([]string, 0, len(*value.target))
+	for _, item := range *value.target {
+		text, _ := P(&item).MarshalText()
+		items = append(items, string(text))
+	}
+	return strings.Join(items, ",")
+}
+
+func (value listValue[T, P]) Set(text string) error {
+	var items []T
+	for _, part := range strings.Split(text, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		var item T
+		if err := P(&item).UnmarshalText([]byte(part)); err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+
+	*value.target = items
+	return nil
+}
+
+// basicValue is a flag.Value setting strings, numbers and booleans.
+type basicValue[T string | int | int64 | float64 | bool] struct {
+	target *T
+}
+
+func (value basicValue[T]) String() string {
+	if value.target == nil {
+		return ""
+	}
+	return fmt.Sprint(*value.target)
+}
+
+func (value basicValue[T]) Set(text string) error {
+	var parsed any
+	var err error
+	switch any(*value.target).(type) {
+	case string:
+		parsed = text
+	case int:
+		parsed, err = strconv.Atoi(text)
+	case int64:
+		parsed, err = strconv.ParseInt(text, 10, 64)
+	case float64:
+		parsed, err = strconv.ParseFloat(text, 64)
+	case bool:
+		parsed, err = strconv.ParseBool(text)
+	}
+	if err != nil {
+		return err
+	}
+
+	*value.target = parsed.(T)
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be given without a value.
+func (value basicValue[T]) IsBoolFlag() bool {
+	_, isBool := any(value.target).(*bool)
+	return isBool
+}
+
+// stringsValue is a flag.Value setting a comma-separated list of strings.
+type stringsValue struct {
+	target *[]string
+}
+
+func (value stringsValue) String() string {
+	if value.target == nil {
+		return ""
+	}
+	return strings.Join(*value.target, ",")
+}
+
+func (value stringsValue) Set(text string) error {
+	var items []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	*value.target = items
+	return nil
+}
@@ -0,0 +1,221 @@
+// Package filter checks user-supplied text against operator-defined rules before it is rendered.
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/menzerath/mcgen/metrics"
+)
+
+// list of errors returned by filters
+var (
+	ErrRejected = fmt.Errorf("text rejected by content filter")
+)
+
+// A Filter checks text before it is rendered.
+// Implementations must be safe for concurrent use.
+type Filter interface {
+	// Apply checks the given text and returns it, possibly with some characters masked.
+	// It returns an error wrapping ErrRejected if the text must not be rendered at all.
+	Apply(text string) (string, error)
+}
+
+// An Action defines what happens to text matching a rule.
+type Action string
+
+// Action constants.
+const (
+	ActionReject Action = "reject"
+	ActionMask   Action = "mask"
+	ActionLog    Action = "log"
+)
+
+// maskCharacter replaces every masked character.
+const maskCharacter = '*'
+
+// A Rule matches text either by a list of words or by a regular expression, both matched against the normalised text (see Normalise).
+// Words only match whole words, while patterns match anywhere in the normalised text with its digits kept.
+type Rule struct {
+	Name    string   `json:"name"`
+	Words   []string `json:"words"`
+	Pattern string   `json:"pattern"`
+	Action  Action   `json:"action"`
+
+	pattern *regexp.Regexp
+	words   []string
+}
+
+// Config is the content of a filter file.
+type Config struct {
+	Rules []Rule `json:"rules"`
+
+	// Allow lists words that never match any rule, even if a rule's word or pattern is part of them.
+	Allow []string `json:"allow"`
+}
+
+// RuleFilter is a Filter applying a list of rules.
+type RuleFilter struct {
+	rules []Rule
+	allow []string
+}
+
+// Load reads a filter configuration from the given JSON file.
+func Load(path string) (*RuleFilter, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading filter: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("decoding filter: %w", err)
+	}
+	return New(config)
+}
+
+// New returns a filter applying the given configuration.
+// It returns an error if any rule is invalid.
+func New(config Config) (*RuleFilter, error) {
+	filter := &RuleFilter{}
+	for i, rule := range config.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		switch rule.Action {
+		case ActionReject, ActionMask, ActionLog:
+		case "":
+			rule.Action = ActionReject
+		default:
+			return nil, fmt.Errorf("rule %s: unknown action %q", rule.Name, rule.Action)
+		}
+		if len(rule.Words) == 0 && rule.Pattern == "" {
+			return nil, fmt.Errorf("rule %s: neither words nor pattern given", rule.Name)
+		}
+
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: compiling pattern: %w", rule.Name, err)
+			}
+			rule.pattern = pattern
+		}
+		for _, word := range rule.Words {
+			if normalised := Normalise(word).String(); normalised != "" {
+				rule.words = append(rule.words, normalised)
+			}
+		}
+
+		filter.rules = append(filter.rules, rule)
+	}
+	for _, word := range config.Allow {
+		if normalised := Normalise(word).String(); normalised != "" {
+			filter.allow = append(filter.allow, normalised)
+		}
+	}
+
+	slog.Debug("loaded content filter", "rules", len(filter.rules), "allowed", len(filter.allow))
+	return filter, nil
+}
+
+// Apply checks the given text against all rules.
+// Rules are applied in order; the first matching reject rule rejects the text.
+func (filter *RuleFilter) Apply(text string) (string, error) {
+	normalised := Normalise(text)
+	allowed := filter.allowedRanges(normalised.String())
+	masked := []rune(text)
+
+	for _, rule := range filter.rules {
+		var matches [][2]int
+		for _, match := range rule.matches(normalised) {
+			if !within(match, allowed) {
+				matches = append(matches, match)
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		metrics.FilterHits.WithLabelValues(rule.Name, string(rule.Action)).Inc()
+
+		switch rule.Action {
+		case ActionReject:
+			slog.Warn("text rejected by content filter", "rule", rule.Name)
+			return "", fmt.Errorf("%w: rule %s", ErrRejected, rule.Name)
+		case ActionMask:
+			for _, match := range matches {
+				for _, position := range normalised.positions[match[0]:match[1]] {
+					masked[position] = maskCharacter
+				}
+			}
+		case ActionLog:
+			slog.Warn("text matched content filter", "rule", rule.Name, "text", text)
+		}
+	}
+
+	return string(masked), nil
+}
+
+// matches returns the rune ranges of the normalised text matched by the rule.
+func (rule Rule) matches(normalised Normalised) [][2]int {
+	var matches [][2]int
+	for _, word := range rule.words {
+		for _, match := range find(normalised.String(), word) {
+			if normalised.IsWord(match[0], match[1]) {
+				matches = append(matches, match)
+			}
+		}
+	}
+	if rule.pattern != nil {
+		digits := normalised.Digits()
+		for _, match := range rule.pattern.FindAllStringIndex(digits, -1) {
+			if match[0] == match[1] {
+				continue
+			}
+			matches = append(matches, [2]int{runeIndex(digits, match[0]), runeIndex(digits, match[1])})
+		}
+	}
+	return matches
+}
+
+// allowedRanges returns the rune ranges of the normalised text covered by the allow list.
+func (filter *RuleFilter) allowedRanges(content string) [][2]int {
+	var ranges [][2]int
+	for _, word := range filter.allow {
+		ranges = append(ranges, find(content, word)...)
+	}
+	return ranges
+}
+
+// find returns the rune ranges of all occurrences of word in content.
+func find(content string, word string) [][2]int {
+	var ranges [][2]int
+	for offset := 0; offset < len(content); {
+		index := strings.Index(content[offset:], word)
+		if index < 0 {
+			break
+		}
+		start := offset + index
+		ranges = append(ranges, [2]int{runeIndex(content, start), runeIndex(content, start+len(word))})
+		offset = start + 1
+	}
+	return ranges
+}
+
+// runeIndex converts a byte offset into a rune offset.
+func runeIndex(content string, offset int) int {
+	return len([]rune(content[:offset]))
+}
+
+// within reports whether the range lies completely within any of the given ranges.
+func within(match [2]int, ranges [][2]int) bool {
+	for _, other := range ranges {
+		if match[0] >= other[0] && match[1] <= other[1] {
+			return true
+		}
+	}
+	return false
+}
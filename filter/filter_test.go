@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRuleFilterApply(t *testing.T) {
+	filter, err := New(Config{
+		Rules: []Rule{
+			{Name: "slurs", Words: []string{"badword"}, Action: ActionReject},
+			{Name: "swearing", Words: []string{"darn"}, Action: ActionMask},
+			{Name: "phones", Pattern: `\d{7}`, Action: ActionMask},
+			{Name: "urls", Pattern: "https?www", Action: ActionLog},
+		},
+		Allow: []string{"darnwell"},
+	})
+	if err != nil {
+		t.Fatalf("New returned %v", err)
+	}
+
+	tests := []struct {
+		text     string
+		want     string
+		rejected bool
+	}{
+		{"hello world", "hello world", false},
+		{"a badword here", "", true},
+		{"B@dW0rd!", "", true},
+		{"darn it", "**** it", false},
+		{"D.@rn!", "*.***!", false},
+		{"darning", "darning", false},
+		{"darnwell", "darnwell", false},
+		{"call 555-1234", "call ***-****", false},
+		{"call sss-i234", "call sss-i234", false},
+		{"https://www.example.com", "https://www.example.com", false},
+	}
+	for _, test := range tests {
+		masked, err := filter.Apply(test.text)
+		if test.rejected {
+			if !errors.Is(err, ErrRejected) {
+				t.Errorf("Apply(%q) = %q, %v, want %v", test.text, masked, err, ErrRejected)
+			}
+			continue
+		}
+		if err != nil || masked != test.want {
+			t.Errorf("Apply(%q) = %q, %v, want %q", test.text, masked, err, test.want)
+		}
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"unknown action", Rule{Words: []string{"a"}, Action: "delete"}},
+		{"neither words nor pattern", Rule{Action: ActionReject}},
+		{"invalid pattern", Rule{Pattern: "(", Action: ActionReject}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := New(Config{Rules: []Rule{test.rule}}); err == nil {
+				t.Errorf("New accepted %+v", test.rule)
+			}
+		})
+	}
+}
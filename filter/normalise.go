@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"unicode"
+)
+
+// leetspeak maps characters commonly used to disguise letters to the letter they resemble.
+var leetspeak = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'6': 'g',
+	'7': 't',
+	'8': 'b',
+	'9': 'g',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+	'|': 'l',
+	'€': 'e',
+	'£': 'l',
+}
+
+// homoglyphs maps characters of other scripts to the latin letter they look like.
+var homoglyphs = map[rune]rune{
+	// cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j', 'ѕ': 's',
+	// greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+	// latin with diacritics
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ç': 'c', 'è': 'e',
+	'é': 'e', 'ê': 'e', 'ë': 'e', 'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ñ': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ù': 'u', 'ú': 'u', 'û': 'u',
+	'ü': 'u', 'ý': 'y', 'ÿ': 'y',
+}
+
+// Normalised is a text reduced to the lowercase latin letters and digits it resembles.
+type Normalised struct {
+	runes []rune
+
+	// digits is the same text, but with digits kept instead of replaced by the letters they resemble.
+	digits []rune
+
+	// positions maps each normalised rune to the position of its rune in the original text.
+	positions []int
+
+	// letters reports whether the original rune of each normalised rune is a letter, not leetspeak.
+	letters []bool
+
+	// separated reports whether characters were dropped before each normalised rune, and is set after the last one.
+	separated []bool
+}
+
+// Normalise lowercases the given text, replaces leetspeak and homoglyphs by the letters they resemble and drops everything else that is not a letter or digit.
+// This way, "B.@-d" and "bаd" (with a cyrillic a) are both normalised to "bad".
+func Normalise(text string) Normalised {
+	var normalised Normalised
+	separated := true
+	for position, r := range []rune(text) {
+		r = unicode.ToLower(r)
+		if replacement, exists := homoglyphs[r]; exists {
+			r = replacement
+		}
+		// fullwidth forms are commonly used to evade filters as well
+		if r >= 'ａ' && r <= 'ｚ' {
+			r = r - 'ａ' + 'a'
+		}
+		original := r
+		if replacement, exists := leetspeak[r]; exists {
+			r = replacement
+		}
+
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			separated = true
+			continue
+		}
+		digit := r
+		if unicode.IsDigit(original) {
+			digit = original
+		}
+		normalised.runes = append(normalised.runes, r)
+		normalised.digits = append(normalised.digits, digit)
+		normalised.positions = append(normalised.positions, position)
+		normalised.letters = append(normalised.letters, unicode.IsLetter(original))
+		normalised.separated = append(normalised.separated, separated)
+		separated = false
+	}
+	normalised.separated = append(normalised.separated, true)
+	return normalised
+}
+
+// String returns the normalised text.
+func (normalised Normalised) String() string {
+	return string(normalised.runes)
+}
+
+// Digits returns the normalised text with digits kept, e.g. "b4d 42" is "bada2" as String but "b4d42" as Digits.
+// Both have the same length, so rune positions of matches are the same.
+func (normalised Normalised) Digits() string {
+	return string(normalised.digits)
+}
+
+// IsWord reports whether the given rune range of the normalised text is a whole word of the original text.
+// Words are separated by anything dropped by Normalise, and by digits and symbols standing in for letters next to them, e.g. in "bad!".
+func (normalised Normalised) IsWord(start int, end int) bool {
+	startsWord := normalised.separated[start] || !normalised.letters[start-1]
+	endsWord := normalised.separated[end] || !normalised.letters[end]
+	return startsWord && endsWord
+}
@@ -0,0 +1,56 @@
+package filter
+
+import "testing"
+
+func TestNormalise(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   string
+		digits string
+	}{
+		{"", "", ""},
+		{"Bad", "bad", "bad"},
+		{"B.@-d", "bad", "bad"},
+		{"bаd", "bad", "bad"}, // cyrillic a
+		{"ＢＡＤ", "bad", "bad"},
+		{"b4d 42", "bada2", "b4d42"},
+		{"h3ll0!", "helloi", "h3ll0i"},
+		{"Grüne", "grune", "grune"},
+		{"...", "", ""},
+	}
+	for _, test := range tests {
+		normalised := Normalise(test.text)
+		if normalised.String() != test.want {
+			t.Errorf("Normalise(%q).String() = %q, want %q", test.text, normalised.String(), test.want)
+		}
+		if normalised.Digits() != test.digits {
+			t.Errorf("Normalise(%q).Digits() = %q, want %q", test.text, normalised.Digits(), test.digits)
+		}
+		if len(normalised.positions) != len(normalised.runes) {
+			t.Errorf("Normalise(%q) has %d positions for %d runes", test.text, len(normalised.positions), len(normalised.runes))
+		}
+	}
+}
+
+func TestNormalisedIsWord(t *testing.T) {
+	tests := []struct {
+		text       string
+		start, end int
+		want       bool
+	}{
+		{"bad", 0, 3, true},
+		{"a bad day", 1, 4, true},
+		{"badword", 0, 3, false},
+		{"abad", 1, 4, false},
+		{"bad!", 0, 3, true},
+		{"1bad", 1, 4, true},
+		{"cl@ss", 2, 5, false},
+		{"bad.word", 0, 3, true},
+		{"b a d", 0, 3, true},
+	}
+	for _, test := range tests {
+		if isWord := Normalise(test.text).IsWord(test.start, test.end); isWord != test.want {
+			t.Errorf("Normalise(%q).IsWord(%d, %d) = %v, want %v", test.text, test.start, test.end, isWord, test.want)
+		}
+	}
+}
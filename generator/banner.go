@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/menzerath/mcgen/assets"
+	xdraw "golang.org/x/image/draw"
+)
+
+// limits of banners
+const (
+	MaxBannerPatterns  = 16
+	DefaultBannerScale = 8
+	MaxBannerScale     = 32
+)
+
+// list of errors returned when rendering banners
+var (
+	ErrUnknownPattern     = fmt.Errorf("unknown banner pattern")
+	ErrUnknownDyeColor    = fmt.Errorf("unknown dye color")
+	ErrTooManyPatterns    = fmt.Errorf("banners must not have more than %d patterns", MaxBannerPatterns)
+	ErrInvalidBannerScale = fmt.Errorf("scale must be between 1 and %d", MaxBannerScale)
+)
+
+// A Banner is dyed in its base colour and covered by its patterns in order.
+// Patterns and colours are given by the game's IDs, e.g. "minecraft:creeper" and "red"; the namespace is optional.
+type Banner struct {
+	Base     string
+	Patterns []BannerPattern
+}
+
+// A BannerPattern is a layer of a banner, dyed in its colour.
+type BannerPattern struct {
+	Pattern string `json:"pattern"`
+	Color   string `json:"color"`
+}
+
+// GenerateBanner renders the front of a banner at the given scale and returns it as PNG.
+// It will return an error if the banner is invalid, the generator is overloaded or the context is cancelled while waiting.
+func (generator *Generator) GenerateBanner(ctx context.Context, banner Banner, scale int) ([]byte, error) {
+	if scale == 0 {
+		scale = DefaultBannerScale
+	}
+	if scale < 1 || scale > MaxBannerScale {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidBannerScale, scale)
+	}
+
+	release, err := generator.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	front, err := generator.RenderBanner(banner)
+	if err != nil {
+		return nil, err
+	}
+	scaled := image.NewNRGBA(image.Rect(0, 0, front.Bounds().Dx()*scale, front.Bounds().Dy()*scale))
+	xdraw.NearestNeighbor.Scale(scaled, scaled.Bounds(), front, front.Bounds(), xdraw.Src, nil)
+	return encodePNG(scaled)
+}
+
+// RenderBanner renders the front of a banner in the size of the game's texture, see assets.BannerWidth and assets.BannerHeight.
+func (generator *Generator) RenderBanner(banner Banner) (*image.NRGBA, error) {
+	if len(banner.Patterns) > MaxBannerPatterns {
+		return nil, fmt.Errorf("%w: %d", ErrTooManyPatterns, len(banner.Patterns))
+	}
+
+	layers := append([]BannerPattern{{Pattern: "base", Color: banner.Base}}, banner.Patterns...)
+	front := image.NewNRGBA(image.Rect(0, 0, assets.BannerWidth, assets.BannerHeight))
+	for _, layer := range layers {
+		mask, exists := generator.bannerPatterns[vanillaID(layer.Pattern)]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPattern, layer.Pattern)
+		}
+		dye, exists := assets.DyeColors[vanillaID(layer.Color)]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownDyeColor, layer.Color)
+		}
+
+		// blend the dye onto the banner by the pattern's alpha
+		for y := 0; y < assets.BannerHeight; y++ {
+			for x := 0; x < assets.BannerWidth; x++ {
+				_, _, _, a := mask.At(mask.Bounds().Min.X+x, mask.Bounds().Min.Y+y).RGBA()
+				if a == 0 {
+					continue
+				}
+				alpha := float64(a) / 0xffff
+				c := front.NRGBAAt(x, y)
+				front.SetNRGBA(x, y, color.NRGBA{
+					R: uint8(float64(c.R)*(1-alpha) + float64(dye.R)*alpha),
+					G: uint8(float64(c.G)*(1-alpha) + float64(dye.G)*alpha),
+					B: uint8(float64(c.B)*(1-alpha) + float64(dye.B)*alpha),
+					A: 255,
+				})
+			}
+		}
+	}
+	return front, nil
+}
+
+// bannerIcon renders a banner scaled down to fit into the icon of a background.
+func (generator *Generator) bannerIcon(banner Banner) (image.Image, error) {
+	front, err := generator.RenderBanner(banner)
+	if err != nil {
+		return nil, err
+	}
+
+	size := assets.IconBounds.Size()
+	width := size.Y * assets.BannerWidth / assets.BannerHeight
+	icon := image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+	xdraw.CatmullRom.Scale(icon, image.Rect((size.X-width)/2, 0, (size.X+width)/2, size.Y), front, front.Bounds(), xdraw.Src, nil)
+	return icon, nil
+}
+
+// vanillaID returns the given ID without the optional namespace of the game.
+func vanillaID(id string) string {
+	return strings.TrimPrefix(id, assets.MinecraftNamespace+":")
+}
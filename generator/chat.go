@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/menzerath/mcgen/assets"
+)
+
+// ChatType selects how a chat line is shown.
+type ChatType string
+
+// ChatType constants.
+const (
+	ChatRaw     ChatType = "raw"
+	ChatPlayer  ChatType = "chat"
+	ChatSystem  ChatType = "system"
+	ChatDeath   ChatType = "death"
+	ChatWhisper ChatType = "whisper"
+)
+
+// limits of the chat, in pixels of the game's GUI; its width defaults to the game's default width
+const (
+	DefaultChatWidth = 320
+	MinChatWidth     = 40
+	MaxChatWidth     = 320
+	MaxChatLines     = 100
+)
+
+// list of errors returned when rendering the chat
+var (
+	ErrInvalidChatType  = fmt.Errorf("chat type must be raw, chat, system, death or whisper")
+	ErrInvalidChatWidth = fmt.Errorf("chat width must be between %d and %d", MinChatWidth, MaxChatWidth)
+	ErrMissingPlayer    = fmt.Errorf("chat messages, deaths and whispers need a player")
+	ErrEmptyChat        = fmt.Errorf("chats must have at least one line")
+	ErrTooManyChatLines = fmt.Errorf("chats must not have more than %d lines", MaxChatLines)
+)
+
+// layout of the chat, in pixels of the game's GUI which are doubled like the toast's icon
+const (
+	chatLineHeight   = 9
+	chatPaddingLeft  = 4
+	chatPaddingRight = 8
+)
+
+// chatBackground is the game's default chat background, black at half opacity.
+var chatBackground = color.NRGBA{A: 127}
+
+// A ChatLine is a single message in the chat, which is wrapped at the chat's width.
+type ChatLine struct {
+	// Type decides how the player is shown: "<Player> Text" for chat messages, "Player Text" for deaths,
+	// "Player whispers to you: Text" for whispers; system messages are yellow, raw lines are shown as they are.
+	Type ChatType `json:"type"`
+
+	Player string `json:"player,omitempty"`
+
+	// Text may contain the game's formatting codes; it is replaced by the JSON text Component, if set.
+	Text      string          `json:"text,omitempty"`
+	Component json.RawMessage `json:"component,omitempty"`
+}
+
+// spans returns the formatted line including the player.
+func (line ChatLine) spans() ([]TextSpan, error) {
+	base := TextStyle{Color: TextColors["white"]}
+	switch line.Type {
+	case ChatSystem:
+		base.Color = TextColors["yellow"]
+	case ChatWhisper:
+		base = TextStyle{Color: TextColors["gray"], Italic: true}
+	case "", ChatRaw, ChatPlayer, ChatDeath:
+	default:
+		return nil, fmt.Errorf("%w, not %q", ErrInvalidChatType, line.Type)
+	}
+
+	message := ParseFormatting(line.Text, base)
+	if len(line.Component) > 0 {
+		var err error
+		if message, err = ParseTextComponent(line.Component, base); err != nil {
+			return nil, err
+		}
+	}
+
+	prefix := ""
+	switch line.Type {
+	case ChatPlayer:
+		prefix = "<" + line.Player + "> "
+	case ChatDeath:
+		prefix = line.Player + " "
+	case ChatWhisper:
+		prefix = line.Player + " whispers to you: "
+	default:
+		return message, nil
+	}
+	if line.Player == "" {
+		return nil, fmt.Errorf("%w: %s", ErrMissingPlayer, line.Type)
+	}
+	return append([]TextSpan{{Text: prefix, Style: base}}, message...), nil
+}
+
+// GenerateChat renders the given lines like the game's chat at the given width in GUI pixels, or the default width for 0, and returns it as PNG.
+// It will return an error if a line is invalid, the chat is too long, the generator is overloaded or the context is cancelled while waiting.
+func (generator *Generator) GenerateChat(ctx context.Context, lines []ChatLine, width int) ([]byte, error) {
+	if width == 0 {
+		width = DefaultChatWidth
+	}
+	if width < MinChatWidth || width > MaxChatWidth {
+		return nil, fmt.Errorf("%w, not %d", ErrInvalidChatWidth, width)
+	}
+	if len(lines) == 0 {
+		return nil, ErrEmptyChat
+	}
+	if len(lines) > MaxChatLines {
+		return nil, fmt.Errorf("%w, not %d", ErrTooManyChatLines, len(lines))
+	}
+	messages := make([][]TextSpan, 0, len(lines))
+	for _, line := range lines {
+		spans, err := line.spans()
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, spans)
+	}
+
+	release, err := generator.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	img, err := generator.renderChat(messages, width)
+	if err != nil {
+		return nil, err
+	}
+	return encodePNG(img)
+}
+
+// renderChat wraps the messages at the given width and draws them onto the chat's background, oldest at the top.
+func (generator *Generator) renderChat(messages [][]TextSpan, width int) (image.Image, error) {
+	// we need to lock this because the freetype library is not thread-safe
+	generator.imageWritingLock.Lock()
+	defer generator.imageWritingLock.Unlock()
+
+	face, err := generator.fontFace(assets.DefaultFont)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines [][]TextSpan
+	for _, message := range messages {
+		lines = append(lines, wrapLine(face, message, width*iconScale)...)
+	}
+	if len(lines) > MaxChatLines {
+		return nil, fmt.Errorf("%w, not %d once wrapped", ErrTooManyChatLines, len(lines))
+	}
+
+	bounds := image.Rect(0, 0, chatPaddingLeft+width+chatPaddingRight, len(lines)*chatLineHeight)
+	img := image.NewNRGBA(scaleGUI(bounds))
+	draw.Draw(img, img.Bounds(), image.NewUniform(chatBackground), image.Point{}, draw.Src)
+	for i, line := range lines {
+		// the glyphs end one pixel above the bottom of their line
+		baseline := (i+1)*chatLineHeight - 1
+		drawLine(img, face, line, image.Pt(chatPaddingLeft, baseline).Mul(iconScale), true)
+	}
+	return img, nil
+}
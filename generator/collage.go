@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+)
+
+// limits for collages
+const (
+	MaxCollageAchievements = 32
+	MaxCollageColumns      = 8
+	MaxCollagePadding      = 256
+)
+
+// list of errors returned when generating collages
+var (
+	ErrNoAchievements      = fmt.Errorf("no achievements")
+	ErrTooManyAchievements = fmt.Errorf("too many achievements")
+	ErrInvalidColumns      = fmt.Errorf("invalid number of columns")
+	ErrInvalidPadding      = fmt.Errorf("invalid padding")
+)
+
+// An Achievement describes the content of a single achievement toast.
+type Achievement struct {
+	Background string
+	Title      string
+	Text       string
+	Icon       IconModifiers
+
+	// Head replaces the background by a player's head on the default frame, if set.
+	Head *Head
+
+	// Banner replaces the background by a banner on the default frame, if set.
+	Banner *Banner
+}
+
+// CollageOptions control how multiple achievements are arranged in a single image.
+type CollageOptions struct {
+	// Columns is the number of columns of the grid.
+	// Zero or one results in a vertical stack, just like the game shows multiple toasts.
+	Columns int
+
+	// Padding is the amount of transparent pixels added around the whole image.
+	Padding int
+}
+
+// GenerateCollage generates a single image containing all given achievements in order.
+// Achievements are arranged row by row, filling each row from left to right.
+// It will return an error if any background is unknown or the options are out of range.
+func (generator *Generator) GenerateCollage(ctx context.Context, achievements []Achievement, options CollageOptions) ([]byte, error) {
+	if len(achievements) == 0 {
+		return nil, ErrNoAchievements
+	}
+	if len(achievements) > MaxCollageAchievements {
+		return nil, ErrTooManyAchievements
+	}
+	if options.Columns < 0 || options.Columns > MaxCollageColumns {
+		return nil, ErrInvalidColumns
+	}
+	if options.Padding < 0 || options.Padding > MaxCollagePadding {
+		return nil, ErrInvalidPadding
+	}
+
+	release, err := generator.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	columns := max(options.Columns, 1)
+	columns = min(columns, len(achievements))
+	rows := (len(achievements) + columns - 1) / columns
+
+	// render all achievements first, so we know the size of a single toast
+	toasts := make([]image.Image, 0, len(achievements))
+	for _, achievement := range achievements {
+		toast, err := generator.render(achievement)
+		if err != nil {
+			return nil, err
+		}
+		toasts = append(toasts, toast)
+	}
+	toastSize := toasts[0].Bounds().Size()
+
+	// the game places stacked toasts directly below each other, so we do not add any gaps in between
+	collage := image.NewRGBA(image.Rect(
+		0,
+		0,
+		columns*toastSize.X+2*options.Padding,
+		rows*toastSize.Y+2*options.Padding,
+	))
+	for i, toast := range toasts {
+		offset := image.Pt(
+			options.Padding+(i%columns)*toastSize.X,
+			options.Padding+(i/columns)*toastSize.Y,
+		)
+		draw.Draw(collage, toast.Bounds().Sub(toast.Bounds().Min).Add(offset), toast, toast.Bounds().Min, draw.Over)
+	}
+
+	return encodePNG(collage)
+}
@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// maxComponentDepth limits the nesting of text components.
+const maxComponentDepth = 16
+
+// ErrInvalidComponent is returned for text components that cannot be parsed.
+var ErrInvalidComponent = fmt.Errorf("invalid text component")
+
+// textComponent is a JSON text component of the game.
+// Components without text show the fallback of their translation, or the translation's key.
+type textComponent struct {
+	Text      *string `json:"text"`
+	Translate string  `json:"translate"`
+	Fallback  string  `json:"fallback"`
+
+	Color         string `json:"color"`
+	Bold          *bool  `json:"bold"`
+	Italic        *bool  `json:"italic"`
+	Underlined    *bool  `json:"underlined"`
+	Strikethrough *bool  `json:"strikethrough"`
+	Obfuscated    *bool  `json:"obfuscated"`
+
+	Extra []json.RawMessage `json:"extra"`
+}
+
+// ParseTextComponent parses a JSON text component of the game, e.g. {"text":"Hello","color":"gold","extra":[" world"]}, into spans starting in the base style.
+// Like in the game, plain strings and lists are components as well, and the text may contain formatting codes.
+func ParseTextComponent(data []byte, base TextStyle) ([]TextSpan, error) {
+	return componentSpans(data, base, 0)
+}
+
+func componentSpans(data json.RawMessage, style TextStyle, depth int) ([]TextSpan, error) {
+	if depth > maxComponentDepth {
+		return nil, fmt.Errorf("%w: nested deeper than %d", ErrInvalidComponent, maxComponentDepth)
+	}
+	data = json.RawMessage(strings.TrimSpace(string(data)))
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidComponent)
+	}
+
+	switch data[0] {
+	case '"':
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidComponent, err)
+		}
+		return ParseFormatting(text, style), nil
+	case '[':
+		// the first component is the parent of all following ones, which inherit its style
+		var list []json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidComponent, err)
+		}
+		if len(list) == 0 {
+			return nil, nil
+		}
+		parent, err := listParent(list)
+		if err != nil {
+			return nil, err
+		}
+		return componentSpans(parent, style, depth+1)
+	}
+
+	var component textComponent
+	if err := json.Unmarshal(data, &component); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidComponent, err)
+	}
+	style, err := component.style(style)
+	if err != nil {
+		return nil, err
+	}
+
+	text := component.Translate
+	switch {
+	case component.Text != nil:
+		text = *component.Text
+	case component.Fallback != "":
+		text = component.Fallback
+	}
+	spans := ParseFormatting(text, style)
+	for _, extra := range component.Extra {
+		extraSpans, err := componentSpans(extra, style, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, extraSpans...)
+	}
+	return spans, nil
+}
+
+// listParent returns the first component of the list with all following ones appended to its extra components.
+func listParent(list []json.RawMessage) (json.RawMessage, error) {
+	first := json.RawMessage(strings.TrimSpace(string(list[0])))
+	parent := map[string]any{"text": ""}
+	var extra []json.RawMessage
+	switch {
+	case len(first) > 0 && first[0] == '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(first, &fields); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidComponent, err)
+		}
+		if raw, exists := fields["extra"]; exists {
+			if err := json.Unmarshal(raw, &extra); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidComponent, err)
+			}
+		}
+		for key, value := range fields {
+			parent[key] = value
+		}
+	case len(first) > 0 && first[0] == '"':
+		parent["text"] = first
+	default:
+		// lists and invalid components are reported when parsing the extra components
+		extra = []json.RawMessage{first}
+	}
+	parent["extra"] = append(extra, list[1:]...)
+
+	data, err := json.Marshal(parent)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidComponent, err)
+	}
+	return data, nil
+}
+
+// style returns the given parent's style overridden by the component's colour and formatting.
+func (component textComponent) style(parent TextStyle) (TextStyle, error) {
+	style := parent
+	if component.Color != "" {
+		c, err := textColor(component.Color)
+		if err != nil {
+			return TextStyle{}, err
+		}
+		style.Color = c
+	}
+	for _, format := range []struct {
+		value  *bool
+		target *bool
+	}{
+		{component.Bold, &style.Bold},
+		{component.Italic, &style.Italic},
+		{component.Underlined, &style.Underlined},
+		{component.Strikethrough, &style.Strikethrough},
+		{component.Obfuscated, &style.Obfuscated},
+	} {
+		if format.value != nil {
+			*format.target = *format.value
+		}
+	}
+	return style, nil
+}
+
+// textColor returns the colour of the given name or hex code, e.g. "gold" or "#ffaa00".
+func textColor(name string) (color.Color, error) {
+	if c, exists := TextColors[name]; exists {
+		return c, nil
+	}
+	if strings.HasPrefix(name, "#") {
+		c, err := ParseHexColor(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidComponent, err)
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("%w: unknown color %q", ErrInvalidComponent, name)
+}
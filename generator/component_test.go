@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"errors"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTextComponent(t *testing.T) {
+	base := TextStyle{Color: TextColors["white"]}
+	gold := TextStyle{Color: TextColors["gold"]}
+	tests := []struct {
+		name string
+		data string
+		want []TextSpan
+	}{
+		{"string", `"Hello"`, []TextSpan{{Text: "Hello", Style: base}}},
+		{"string with codes", `"§cHi"`, []TextSpan{{Text: "Hi", Style: TextStyle{Color: TextColors["red"]}}}},
+		{"text", `{"text":"Hello","color":"gold"}`, []TextSpan{{Text: "Hello", Style: gold}}},
+		{"empty text", `{"text":""}`, nil},
+		{"hex colour", `{"text":"a","color":"#102030"}`, []TextSpan{{Text: "a", Style: TextStyle{Color: color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}}}}},
+		{
+			"extra inherits the style",
+			`{"text":"a","color":"gold","bold":true,"extra":["b",{"text":"c","bold":false}]}`,
+			[]TextSpan{
+				{Text: "a", Style: TextStyle{Color: gold.Color, Bold: true}},
+				{Text: "b", Style: TextStyle{Color: gold.Color, Bold: true}},
+				{Text: "c", Style: gold},
+			},
+		},
+		{
+			"list inherits the first",
+			`[{"text":"a","color":"gold"},"b"]`,
+			[]TextSpan{{Text: "a", Style: gold}, {Text: "b", Style: gold}},
+		},
+		{
+			"list keeps extra of the first",
+			`[{"text":"a","color":"gold","extra":["b"]},{"text":"c","bold":true}]`,
+			[]TextSpan{{Text: "a", Style: gold}, {Text: "b", Style: gold}, {Text: "c", Style: TextStyle{Color: gold.Color, Bold: true}}},
+		},
+		{"list of strings", `["a","b"]`, []TextSpan{{Text: "a", Style: base}, {Text: "b", Style: base}}},
+		{"nested list", `[["a"],"b"]`, []TextSpan{{Text: "a", Style: base}, {Text: "b", Style: base}}},
+		{"empty list", `[]`, nil},
+		{"translation fallback", `{"translate":"chat.type.text","fallback":"Hi"}`, []TextSpan{{Text: "Hi", Style: base}}},
+		{"translation key", `{"translate":"chat.type.text"}`, []TextSpan{{Text: "chat.type.text", Style: base}}},
+		{
+			"all formatting",
+			`{"text":"a","italic":true,"underlined":true,"strikethrough":true,"obfuscated":true}`,
+			[]TextSpan{{Text: "a", Style: TextStyle{Color: base.Color, Italic: true, Underlined: true, Strikethrough: true, Obfuscated: true}}},
+		},
+		{"surrounding spaces", " \n\"a\" ", []TextSpan{{Text: "a", Style: base}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spans, err := ParseTextComponent([]byte(test.data), base)
+			if err != nil {
+				t.Fatalf("ParseTextComponent(%s) returned %v", test.data, err)
+			}
+			if !reflect.DeepEqual(spans, test.want) {
+				t.Errorf("ParseTextComponent(%s) = %+v, want %+v", test.data, spans, test.want)
+			}
+		})
+	}
+}
+
+func TestParseTextComponentInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ``},
+		{"spaces", `  `},
+		{"no json", `Hello`},
+		{"unterminated string", `"Hello`},
+		{"unterminated object", `{"text":"a"`},
+		{"unknown colour", `{"text":"a","color":"rainbow"}`},
+		{"invalid hex colour", `{"text":"a","color":"#12345"}`},
+		{"invalid extra", `{"text":"a","extra":[42]}`},
+		{"invalid list entry", `["a",{"text":"b","color":"rainbow"}]`},
+		{"wrong type", `{"text":42}`},
+		{"nested too deep", strings.Repeat("[", maxComponentDepth+2) + `"a"` + strings.Repeat("]", maxComponentDepth+2)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if spans, err := ParseTextComponent([]byte(test.data), TextStyle{}); !errors.Is(err, ErrInvalidComponent) {
+				t.Errorf("ParseTextComponent(%s) = %+v, %v, want %v", test.data, spans, err, ErrInvalidComponent)
+			}
+		})
+	}
+}
@@ -3,14 +3,20 @@ package generator
 
 import (
 	"bytes"
+	"context"
+	"embed"
 	"fmt"
 	"image"
 	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
 	"image/png"
 	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 
-	"github.com/fogleman/gg"
 	"github.com/golang/freetype/truetype"
 	"github.com/menzerath/mcgen/assets"
 	"golang.org/x/image/font"
@@ -19,90 +25,386 @@ import (
 // list of errors returned by the generator
 var (
 	ErrUnknownBackground = fmt.Errorf("unknown background")
+	ErrAssetExists       = fmt.Errorf("asset already exists")
+	ErrDefaultAsset      = fmt.Errorf("default assets cannot be removed")
 )
 
 // A Generator manages all resources required to generate achievement images and provides a method to generate them.
 type Generator struct {
-	Backgrounds map[string]image.Image
-	FontFace    font.Face
+	Assets *Registry
 
+	// faces caches a face per font; it is guarded by imageWritingLock and cleared on changed fonts.
+	faces map[string]font.Face
+	glint image.Image
+
+	// bannerPatterns holds the masks of all banner patterns by their name.
+	bannerPatterns map[string]image.Image
+
+	options          atomic.Pointer[Options]
 	imageWritingLock sync.Mutex
+	limiter          *limiter
+}
+
+// Options configure a generator.
+// Zero values are replaced by the defaults returned by DefaultOptions.
+type Options struct {
+	FontSize    float64
+	TitleColor  color.Color
+	TextColor   color.Color
+	TitleOffset image.Point
+	TextOffset  image.Point
+
+	// IconMappings map legacy icon IDs and item IDs to backgrounds; they default to the built-in ones.
+	IconMappings *assets.IconMappings
+
+	Concurrency ConcurrencyLimits
+
+	// Packs are merged with the embedded assets in the given order.
+	Packs []assets.Pack
+	// OverrideAssets allows packs to replace existing assets of the same name instead of failing.
+	OverrideAssets bool
+}
+
+// DefaultOptions returns the options resembling the game's achievements.
+// Concurrency is not limited by default.
+func DefaultOptions() Options {
+	return Options{
+		FontSize:    16,
+		TitleColor:  color.RGBA{R: 255, G: 255, B: 0, A: 255},
+		TextColor:   color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		TitleOffset: image.Pt(60, 28),
+		TextOffset:  image.Pt(60, 50),
+	}
+}
+
+// withDefaults returns the options with all zero values replaced by their defaults.
+func (options Options) withDefaults() Options {
+	defaults := DefaultOptions()
+	if options.FontSize <= 0 {
+		options.FontSize = defaults.FontSize
+	}
+	if options.TitleColor == nil {
+		options.TitleColor = defaults.TitleColor
+	}
+	if options.TextColor == nil {
+		options.TextColor = defaults.TextColor
+	}
+	if options.TitleOffset == (image.Point{}) {
+		options.TitleOffset = defaults.TitleOffset
+	}
+	if options.TextOffset == (image.Point{}) {
+		options.TextOffset = defaults.TextOffset
+	}
+	if options.IconMappings == nil {
+		options.IconMappings = assets.DefaultIconMappings
+	}
+	return options
 }
 
 // New returns a new generator.
-// It loads all embedded assets for quick access when needed.
-func New() (*Generator, error) {
+// It loads all embedded assets and merges them with the given packs for quick access when needed.
+func New(options Options) (*Generator, error) {
+	options = options.withDefaults()
 	generator := &Generator{
-		Backgrounds: make(map[string]image.Image),
+		Assets:         NewRegistry(),
+		faces:          make(map[string]font.Face),
+		bannerPatterns: make(map[string]image.Image),
+		limiter:        newLimiter(options.Concurrency),
+	}
+	generator.options.Store(&options)
+
+	// drop cached faces of fonts that were replaced or removed
+	generator.Assets.Subscribe(func(change Change) {
+		if change.Kind != KindFont {
+			return
+		}
+		generator.imageWritingLock.Lock()
+		delete(generator.faces, change.Name)
+		generator.imageWritingLock.Unlock()
+	})
+
+	// read all embedded background and frame files and put them into our generator's registry
+	if err := readEmbeddedImages(assets.Backgrounds, "backgrounds", generator.Assets.AddBackground); err != nil {
+		return nil, err
+	}
+	if err := readEmbeddedImages(assets.Frames, "frames", generator.Assets.AddFrame); err != nil {
+		return nil, err
+	}
+	slog.Debug("loaded all backgrounds", "count", len(generator.Assets.Names(KindBackground)))
+
+	// parse the font and store it in our generator's registry
+	parsedFont, err := truetype.Parse(assets.FontFile)
+	if err != nil {
+		return nil, fmt.Errorf("parsing font: %w", err)
+	}
+	if err := generator.Assets.AddFont(assets.DefaultFont, parsedFont, false); err != nil {
+		return nil, err
+	}
+	slog.Debug("loaded font")
+
+	generator.glint, _, err = image.Decode(bytes.NewReader(assets.Glint))
+	if err != nil {
+		return nil, fmt.Errorf("decoding glint: %w", err)
+	}
+	err = readEmbeddedImages(assets.BannerPatterns, "banners", func(name string, img image.Image, _ bool) error {
+		generator.bannerPatterns[name] = img
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// read all embedded background files and put them into our generator's map
-	files, err := assets.Backgrounds.ReadDir("backgrounds")
+	for _, pack := range options.Packs {
+		if err := generator.Assets.AddPack(pack, options.OverrideAssets); err != nil {
+			return nil, fmt.Errorf("adding pack %s: %w", pack.Path, err)
+		}
+	}
+
+	return generator, nil
+}
+
+// readEmbeddedImages decodes all images of the given embedded directory and adds them by their name without extension.
+func readEmbeddedImages(files embed.FS, dir string, add func(name string, img image.Image, replace bool) error) error {
+	entries, err := files.ReadDir(dir)
 	if err != nil {
-		return nil, fmt.Errorf("reading backgrounds: %w", err)
+		return fmt.Errorf("reading %s: %w", dir, err)
 	}
-	for _, file := range files {
-		content, err := assets.Backgrounds.ReadFile(fmt.Sprintf("backgrounds/%s", file.Name()))
+	for _, entry := range entries {
+		content, err := files.ReadFile(fmt.Sprintf("%s/%s", dir, entry.Name()))
 		if err != nil {
-			return nil, fmt.Errorf("reading background %s: %w", file.Name(), err)
+			return fmt.Errorf("reading %s: %w", entry.Name(), err)
 		}
 
-		background, _, err := image.Decode(bytes.NewReader(content))
+		img, _, err := image.Decode(bytes.NewReader(content))
 		if err != nil {
-			return nil, fmt.Errorf("decoding background %s: %w", file.Name(), err)
+			return fmt.Errorf("decoding %s: %w", entry.Name(), err)
+		}
+
+		if err := add(strings.TrimSuffix(entry.Name(), ".png"), img, false); err != nil {
+			return fmt.Errorf("adding %s: %w", entry.Name(), err)
 		}
+		slog.Debug("loaded image", "directory", dir, "name", entry.Name())
+	}
+	return nil
+}
 
-		generator.Backgrounds[file.Name()] = background
-		slog.Debug("loaded background", "name", file.Name())
+// fontFace returns the cached face of the font used for the given background.
+// The caller must hold imageWritingLock.
+func (generator *Generator) fontFace(background string) (font.Face, error) {
+	name, exists := generator.Assets.FontFor(background)
+	if !exists {
+		return nil, fmt.Errorf("missing font %s", name)
+	}
+	if face, exists := generator.faces[name]; exists {
+		return face, nil
 	}
-	slog.Debug("loaded all backgrounds", "count", len(generator.Backgrounds))
 
-	// parse the font and store it in our generator
-	parsedFont, err := truetype.Parse(assets.FontFile)
-	if err != nil {
-		return nil, fmt.Errorf("parsing font: %w", err)
+	parsedFont, exists := generator.Assets.Font(name)
+	if !exists {
+		return nil, fmt.Errorf("missing font %s", name)
 	}
-	generator.FontFace = truetype.NewFace(
+	face := truetype.NewFace(
 		parsedFont,
 		&truetype.Options{
-			Size:    16,
+			Size:    generator.options.Load().FontSize,
 			Hinting: font.HintingFull,
 		},
 	)
-	slog.Debug("loaded font")
+	generator.faces[name] = face
+	return face, nil
+}
 
-	return generator, nil
+// Reload replaces the colors of title and text and the icon mappings used for all following generations.
+// They are replaced at once, so generations see either all old or all new values. Nil values are replaced by their defaults.
+func (generator *Generator) Reload(title color.Color, text color.Color, mappings *assets.IconMappings) {
+	options := *generator.options.Load()
+	options.TitleColor = title
+	options.TextColor = text
+	options.IconMappings = mappings
+	options = options.withDefaults()
+	generator.options.Store(&options)
+}
+
+// LegacyIcon returns the background name the given legacy icon ID is currently mapped to.
+func (generator *Generator) LegacyIcon(id string) (string, bool) {
+	return generator.options.Load().IconMappings.LegacyIcon(id)
+}
+
+// HasBackground reports whether a background with the given name or item ID exists.
+func (generator *Generator) HasBackground(name string) bool {
+	_, _, exists := generator.background(name)
+	return exists
+}
+
+// background returns the background with the given name and the name it is registered by.
+// Names unknown to the registry are looked up as item or block IDs, e.g. "minecraft:diamond_sword".
+func (generator *Generator) background(name string) (image.Image, string, bool) {
+	if background, exists := generator.Assets.Background(name); exists {
+		return background, name, true
+	}
+	if mapped, exists := generator.options.Load().IconMappings.ItemIcon(name); exists {
+		background, exists := generator.Assets.Background(mapped)
+		return background, mapped, exists
+	}
+	return nil, "", false
 }
 
 // Generate generates an achievement image with the given background and text.
-// It will return an error if the background is unknown.
-func (generator *Generator) Generate(background string, textTop string, textBottom string) ([]byte, error) {
-	// load background template
-	template, exists := generator.Backgrounds[fmt.Sprintf("%s.png", background)]
+// It will return an error if the background is unknown, the generator is overloaded or the context is cancelled while waiting.
+func (generator *Generator) Generate(ctx context.Context, background string, textTop string, textBottom string) ([]byte, error) {
+	return generator.GenerateAchievement(ctx, Achievement{
+		Background: background,
+		Title:      textTop,
+		Text:       textBottom,
+	})
+}
+
+// GenerateAchievement generates an achievement image, including the modifiers of its icon.
+// It will return an error if the background is unknown, the modifiers do not apply to it, the generator is overloaded or the context is cancelled while waiting.
+func (generator *Generator) GenerateAchievement(ctx context.Context, achievement Achievement) ([]byte, error) {
+	release, err := generator.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	img, err := generator.render(achievement)
+	if err != nil {
+		return nil, err
+	}
+	return encodePNG(img)
+}
+
+// GenerateAnimated generates an achievement as animated GIF showing the moving glint of an enchanted icon.
+// Achievements without glint result in a single frame.
+func (generator *Generator) GenerateAnimated(ctx context.Context, achievement Achievement) ([]byte, error) {
+	release, err := generator.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	frames := 1
+	if achievement.Icon.Enchanted {
+		frames = glintFrames
+	}
+	images := make([]image.Image, 0, frames)
+	for frame := range frames {
+		img, err := generator.renderFrame(achievement, frame)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+
+	options := generator.options.Load()
+	return encodeGIF(images, glintDelay, options.TitleColor, options.TextColor, color.White, countShadow)
+}
+
+// template returns the background of the given achievement and its resolved name.
+// Achievements showing a head or banner use the default frame instead of their background.
+func (generator *Generator) template(achievement Achievement) (image.Image, string, error) {
+	var icon image.Image
+	var err error
+	switch {
+	case achievement.Head != nil:
+		icon, err = RenderHead(*achievement.Head)
+	case achievement.Banner != nil:
+		icon, err = generator.bannerIcon(*achievement.Banner)
+	default:
+		template, name, exists := generator.background(achievement.Background)
+		if !exists {
+			return nil, "", ErrUnknownBackground
+		}
+		return template, name, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	frame, exists := generator.Assets.FrameFor(assets.DefaultFrame)
 	if !exists {
-		return nil, ErrUnknownBackground
+		return nil, "", fmt.Errorf("%w for icon", ErrMissingFrame)
+	}
+	return drawIcon(frame, icon), assets.DefaultFrame, nil
+}
+
+// render draws a single achievement and returns the resulting image.
+func (generator *Generator) render(achievement Achievement) (image.Image, error) {
+	return generator.renderFrame(achievement, 0)
+}
+
+// renderFrame draws a single achievement with its glint at the given frame of the animation and returns the resulting image.
+func (generator *Generator) renderFrame(achievement Achievement, frame int) (image.Image, error) {
+	// load background template
+	template, name, err := generator.template(achievement)
+	if err != nil {
+		return nil, err
+	}
+	if !achievement.Icon.IsZero() {
+		template, err = generator.modifyIcon(template, name, achievement.Icon, frame)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	dc := gg.NewContextForImage(template)
-	dc.SetFontFace(generator.FontFace)
+	img := image.NewRGBA(image.Rectangle{Max: template.Bounds().Size()})
+	draw.Draw(img, img.Bounds(), template, template.Bounds().Min, draw.Src)
 
-	// write text on background
-	// we need to lock this because the freetype library (used by gg when running DrawString) is not thread-safe
+	// write text on background, using the same layout as tooltips, chat and signs but without formatting codes
+	// we need to lock this because the freetype library is not thread-safe
 	generator.imageWritingLock.Lock()
+	defer generator.imageWritingLock.Unlock()
 
-	dc.SetColor(color.RGBA{R: 255, G: 255, B: 0, A: 255})
-	dc.DrawString(textTop, 60, 28)
+	face, err := generator.fontFace(name)
+	if err != nil {
+		return nil, err
+	}
+	drawCount(img, face, achievement.Icon.Count, assets.IconBounds.Min)
 
-	dc.SetColor(color.RGBA{R: 255, G: 255, B: 255, A: 255})
-	dc.DrawString(textBottom, 60, 50)
+	options := generator.options.Load()
+	drawLine(img, face, []TextSpan{{Text: achievement.Title, Style: TextStyle{Color: options.TitleColor}}}, options.TitleOffset, false)
+	drawLine(img, face, []TextSpan{{Text: achievement.Text, Style: TextStyle{Color: options.TextColor}}}, options.TextOffset, false)
 
-	generator.imageWritingLock.Unlock()
+	return img, nil
+}
 
-	// encode image as bytes
+// encodePNG encodes the given image as PNG and returns its bytes.
+func encodePNG(img image.Image) ([]byte, error) {
 	buffer := new(bytes.Buffer)
-	if err := png.Encode(buffer, dc.Image()); err != nil {
+	if err := png.Encode(buffer, img); err != nil {
 		return nil, fmt.Errorf("encoding image: %w", err)
 	}
 
 	return buffer.Bytes(), nil
 }
+
+// encodeGIF encodes the given images as frames of an animated GIF, shown for the given delay in 1/100 seconds each.
+// The frames share a palette containing the frame's and text's colors exactly, approximating all others.
+func encodeGIF(images []image.Image, delay int, textColors ...color.Color) ([]byte, error) {
+	colors := color.Palette{
+		color.Transparent,
+		color.RGBA{A: 255},
+		color.RGBA{R: 85, G: 85, B: 85, A: 255},
+		color.RGBA{R: 33, G: 33, B: 33, A: 255},
+	}
+	colors = append(colors, textColors...)
+	colors = append(colors, palette.WebSafe...)
+	for gray := 8; len(colors) < 256; gray += 8 {
+		colors = append(colors, color.Gray{Y: uint8(gray)})
+	}
+
+	animation := &gif.GIF{}
+	for _, img := range images {
+		frame := image.NewPaletted(img.Bounds(), colors)
+		draw.FloydSteinberg.Draw(frame, frame.Bounds(), img, img.Bounds().Min)
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, delay)
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := gif.EncodeAll(buffer, animation); err != nil {
+		return nil, fmt.Errorf("encoding image: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
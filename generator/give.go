@@ -0,0 +1,269 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidBannerItem is returned for banners that are not given like the game's items.
+var ErrInvalidBannerItem = fmt.Errorf("invalid banner item")
+
+// bannerPatternsComponent is the item component listing the patterns of a banner.
+const bannerPatternsComponent = "banner_patterns"
+
+// limits of banners given as items, which keep the parser from running out of memory or stack
+const (
+	MaxBannerItemLength = 4096
+	maxSNBTDepth        = 16
+)
+
+// ParseBannerItem parses a banner given as item, either with the game's /give command or just the item, e.g.
+//
+//	/give @p red_banner[banner_patterns=[{pattern:"minecraft:creeper",color:"black"}]]
+//
+// The item's colour is the base of the banner; all components except its patterns are ignored.
+func ParseBannerItem(text string) (Banner, error) {
+	if len(text) > MaxBannerItemLength {
+		return Banner{}, fmt.Errorf("%w: longer than %d characters", ErrInvalidBannerItem, MaxBannerItemLength)
+	}
+	text = strings.TrimSpace(text)
+	if command, found := strings.CutPrefix(strings.TrimPrefix(text, "/"), "give "); found {
+		// skip the target, which may be a selector with arguments in brackets
+		parser := snbtParser{text: strings.TrimSpace(command)}
+		if _, err := parser.token(func(r byte) bool { return r != ' ' && r != '[' }); err != nil {
+			return Banner{}, err
+		}
+		if parser.peek() == '[' {
+			if err := parser.arguments(func(string, any) error { return nil }); err != nil {
+				return Banner{}, err
+			}
+		}
+		text = strings.TrimSpace(parser.text[parser.position:])
+	}
+
+	parser := snbtParser{text: text}
+	item, err := parser.token(func(r byte) bool { return r != ' ' && r != '[' })
+	if err != nil {
+		return Banner{}, err
+	}
+	base, isBanner := strings.CutSuffix(vanillaID(item), "_banner")
+	if !isBanner {
+		return Banner{}, fmt.Errorf("%w: %s is no banner", ErrInvalidBannerItem, item)
+	}
+	banner := Banner{Base: base}
+	if parser.peek() != '[' {
+		return banner, nil
+	}
+
+	err = parser.arguments(func(name string, value any) error {
+		if vanillaID(name) != bannerPatternsComponent {
+			return nil
+		}
+		var err error
+		banner.Patterns, err = bannerPatterns(value)
+		return err
+	})
+	if err != nil {
+		return Banner{}, err
+	}
+	return banner, nil
+}
+
+// bannerPatterns converts the value of the banner_patterns component into patterns.
+func bannerPatterns(value any) ([]BannerPattern, error) {
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be a list", ErrInvalidBannerItem, bannerPatternsComponent)
+	}
+	patterns := make([]BannerPattern, 0, len(list))
+	for _, entry := range list {
+		compound, ok := entry.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: patterns must be compounds", ErrInvalidBannerItem)
+		}
+		pattern, patternOK := compound["pattern"].(string)
+		color, colorOK := compound["color"].(string)
+		if !patternOK || !colorOK {
+			return nil, fmt.Errorf("%w: patterns need a pattern and color", ErrInvalidBannerItem)
+		}
+		patterns = append(patterns, BannerPattern{Pattern: pattern, Color: color})
+	}
+	return patterns, nil
+}
+
+// snbtParser parses the subset of the game's stringified NBT used by item components:
+// compounds, lists and quoted or unquoted strings. Numbers are kept as strings.
+type snbtParser struct {
+	text     string
+	position int
+	depth    int
+}
+
+func (parser *snbtParser) errorf(format string, arguments ...any) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidBannerItem, fmt.Sprintf(format, arguments...), parser.position)
+}
+
+// peek returns the next character, or 0 at the end of the text.
+func (parser *snbtParser) peek() byte {
+	if parser.position >= len(parser.text) {
+		return 0
+	}
+	return parser.text[parser.position]
+}
+
+func (parser *snbtParser) skipSpaces() {
+	for parser.peek() == ' ' {
+		parser.position++
+	}
+}
+
+func (parser *snbtParser) expect(character byte) error {
+	parser.skipSpaces()
+	if parser.peek() != character {
+		return parser.errorf("expected %c", character)
+	}
+	parser.position++
+	return nil
+}
+
+// token returns the following characters as long as they are accepted; it must not be empty.
+func (parser *snbtParser) token(accept func(byte) bool) (string, error) {
+	parser.skipSpaces()
+	start := parser.position
+	for parser.position < len(parser.text) && accept(parser.text[parser.position]) {
+		parser.position++
+	}
+	if parser.position == start {
+		return "", parser.errorf("expected value")
+	}
+	return parser.text[start:parser.position], nil
+}
+
+// value parses a compound as map[string]any, a list as []any or a string.
+func (parser *snbtParser) value() (any, error) {
+	parser.skipSpaces()
+	if next := parser.peek(); next == '{' || next == '[' {
+		if parser.depth == maxSNBTDepth {
+			return nil, parser.errorf("nested deeper than %d", maxSNBTDepth)
+		}
+		parser.depth++
+		defer func() { parser.depth-- }()
+	}
+
+	switch parser.peek() {
+	case '{':
+		parser.position++
+		compound := make(map[string]any)
+		for parser.skipSpaces(); parser.peek() != '}'; parser.skipSpaces() {
+			key, err := parser.key()
+			if err != nil {
+				return nil, err
+			}
+			if err := parser.expect(':'); err != nil {
+				return nil, err
+			}
+			if compound[key], err = parser.value(); err != nil {
+				return nil, err
+			}
+			if err := parser.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+		parser.position++
+		return compound, nil
+	case '[':
+		parser.position++
+		var list []any
+		for parser.skipSpaces(); parser.peek() != ']'; parser.skipSpaces() {
+			entry, err := parser.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, entry)
+			if err := parser.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+		parser.position++
+		return list, nil
+	case '"', '\'':
+		return parser.quoted()
+	}
+	return parser.token(func(r byte) bool {
+		return r != ',' && r != '}' && r != ']' && r != ' '
+	})
+}
+
+// arguments parses arguments given as [name=value,...], like the components of items or the arguments of selectors,
+// and calls the given function for each of them.
+func (parser *snbtParser) arguments(argument func(name string, value any) error) error {
+	if err := parser.expect('['); err != nil {
+		return err
+	}
+	for parser.skipSpaces(); parser.peek() != ']'; parser.skipSpaces() {
+		name, err := parser.token(func(r byte) bool { return r != '=' && r != ' ' })
+		if err != nil {
+			return err
+		}
+		if err := parser.expect('='); err != nil {
+			return err
+		}
+		value, err := parser.value()
+		if err != nil {
+			return err
+		}
+		if err := argument(name, value); err != nil {
+			return err
+		}
+		if err := parser.separator(']'); err != nil {
+			return err
+		}
+	}
+	parser.position++
+	return nil
+}
+
+// key parses the key of a compound's entry.
+func (parser *snbtParser) key() (string, error) {
+	if next := parser.peek(); next == '"' || next == '\'' {
+		return parser.quoted()
+	}
+	return parser.token(func(r byte) bool { return r != ':' && r != ' ' })
+}
+
+// separator consumes the comma between entries, unless the entries are ended by the given character.
+func (parser *snbtParser) separator(end byte) error {
+	parser.skipSpaces()
+	switch parser.peek() {
+	case ',':
+		parser.position++
+		return nil
+	case end:
+		return nil
+	}
+	return parser.errorf("expected , or %c", end)
+}
+
+// quoted parses a string in single or double quotes, which may contain escaped characters.
+func (parser *snbtParser) quoted() (string, error) {
+	quote := parser.peek()
+	parser.position++
+	var builder strings.Builder
+	for parser.position < len(parser.text) {
+		character := parser.text[parser.position]
+		parser.position++
+		switch character {
+		case '\\':
+			if parser.position >= len(parser.text) {
+				return "", parser.errorf("unterminated string")
+			}
+			builder.WriteByte(parser.text[parser.position])
+			parser.position++
+		case quote:
+			return builder.String(), nil
+		default:
+			builder.WriteByte(character)
+		}
+	}
+	return "", parser.errorf("unterminated string")
+}
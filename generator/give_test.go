@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBannerItem(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Banner
+	}{
+		{"plain item", "red_banner", Banner{Base: "red"}},
+		{"namespaced item", "minecraft:light_blue_banner", Banner{Base: "light_blue"}},
+		{"empty components", "white_banner[]", Banner{Base: "white"}},
+		{
+			"give command",
+			`/give @p red_banner[banner_patterns=[{pattern:"minecraft:creeper",color:"black"}]]`,
+			Banner{Base: "red", Patterns: []BannerPattern{{Pattern: "minecraft:creeper", Color: "black"}}},
+		},
+		{
+			"give without slash and selector arguments",
+			`give @a[distance=..5,tag="a]b"] white_banner[banner_patterns=[{pattern:border,color:red}]]`,
+			Banner{Base: "white", Patterns: []BannerPattern{{Pattern: "border", Color: "red"}}},
+		},
+		{
+			"other components and spaces",
+			`black_banner[ custom_name = '{"text":"Flag"}' , minecraft:banner_patterns = [ { color : 'white' , pattern : stripe_top } , {pattern:"base",color:"red"} ] ]`,
+			Banner{Base: "black", Patterns: []BannerPattern{{Pattern: "stripe_top", Color: "white"}, {Pattern: "base", Color: "red"}}},
+		},
+		{
+			"escaped quotes",
+			`red_banner[banner_patterns=[{pattern:"a\"b",color:'it\'s'}]]`,
+			Banner{Base: "red", Patterns: []BannerPattern{{Pattern: `a"b`, Color: "it's"}}},
+		},
+		{"trailing commas", "red_banner[banner_patterns=[{pattern:a,color:b,},],]", Banner{Base: "red", Patterns: []BannerPattern{{Pattern: "a", Color: "b"}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			banner, err := ParseBannerItem(test.text)
+			if err != nil {
+				t.Fatalf("ParseBannerItem(%q) returned %v", test.text, err)
+			}
+			if !reflect.DeepEqual(banner, test.want) {
+				t.Errorf("ParseBannerItem(%q) = %+v, want %+v", test.text, banner, test.want)
+			}
+		})
+	}
+}
+
+func TestParseBannerItemInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"give without item", "/give @p"},
+		{"no banner", "diamond_sword"},
+		{"unclosed components", "red_banner[banner_patterns=[]"},
+		{"missing value", "red_banner[banner_patterns=]"},
+		{"missing equals sign", "red_banner[banner_patterns]"},
+		{"unclosed list", "red_banner[banner_patterns=[{pattern:a,color:b}"},
+		{"unclosed compound", "red_banner[banner_patterns=[{pattern:a,color:b]]"},
+		{"unterminated string", `red_banner[banner_patterns=[{pattern:"a,color:b}]]`},
+		{"trailing backslash", `red_banner[banner_patterns=[{pattern:"a\`},
+		{"missing separator", "red_banner[banner_patterns=[{pattern:a color:b}]]"},
+		{"patterns no list", "red_banner[banner_patterns={pattern:a,color:b}]"},
+		{"pattern no compound", "red_banner[banner_patterns=[creeper]]"},
+		{"pattern without color", "red_banner[banner_patterns=[{pattern:creeper}]]"},
+		{"color no string", "red_banner[banner_patterns=[{pattern:creeper,color:[black]}]]"},
+		{"nested too deep", "red_banner[banner_patterns=" + strings.Repeat("[", maxSNBTDepth+1) + strings.Repeat("]", maxSNBTDepth+1) + "]"},
+		{"selector nested too deep", "/give @p[nbt=" + strings.Repeat("{a:", maxSNBTDepth+1) + strings.Repeat("}", maxSNBTDepth+1) + "] red_banner"},
+		{"too long", "red_banner[banner_patterns=[]," + strings.Repeat("a=b,", MaxBannerItemLength/4) + "]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if banner, err := ParseBannerItem(test.text); !errors.Is(err, ErrInvalidBannerItem) {
+				t.Errorf("ParseBannerItem(%q) = %+v, %v, want %v", test.text, banner, err, ErrInvalidBannerItem)
+			}
+		})
+	}
+}
+
+func TestSNBTParserValue(t *testing.T) {
+	tests := []struct {
+		text string
+		want any
+	}{
+		{"plain", "plain"},
+		{"42b", "42b"},
+		{`"quoted text"`, "quoted text"},
+		{"[]", []any(nil)},
+		{"{}", map[string]any{}},
+		{"[a, [b], {c: d}]", []any{"a", []any{"b"}, map[string]any{"c": "d"}}},
+		{`{"quoted key": 'value', nested: {list: [1, 2]}}`, map[string]any{"quoted key": "value", "nested": map[string]any{"list": []any{"1", "2"}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			parser := snbtParser{text: test.text}
+			value, err := parser.value()
+			if err != nil {
+				t.Fatalf("value() of %q returned %v", test.text, err)
+			}
+			if !reflect.DeepEqual(value, test.want) {
+				t.Errorf("value() of %q = %#v, want %#v", test.text, value, test.want)
+			}
+			if parser.position != len(test.text) {
+				t.Errorf("value() of %q stopped at %d, want %d", test.text, parser.position, len(test.text))
+			}
+		})
+	}
+}
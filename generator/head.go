@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/menzerath/mcgen/assets"
+	xdraw "golang.org/x/image/draw"
+)
+
+// HeadStyle selects how a player's head is shown as icon.
+type HeadStyle string
+
+// HeadStyle constants.
+const (
+	HeadFlat      HeadStyle = "flat"
+	HeadIsometric HeadStyle = "isometric"
+	HeadBody      HeadStyle = "body"
+)
+
+// list of errors returned when rendering heads
+var (
+	ErrInvalidSkin      = fmt.Errorf("skin must be 64x64 or 64x32 pixels")
+	ErrInvalidHeadStyle = fmt.Errorf("head style must be flat, isometric or body")
+)
+
+// skinHeadSize is the size of a head's faces on a skin, in pixels.
+const skinHeadSize = 8
+
+// Positions of the head's faces on a skin; the hat overlay is placed 32 pixels to their right.
+var (
+	skinHeadTop   = image.Pt(8, 0)
+	skinHeadFront = image.Pt(8, 8)
+	skinHeadLeft  = image.Pt(16, 8)
+	skinHatOffset = image.Pt(32, 0)
+)
+
+// Head is a player's head shown instead of the background's icon.
+type Head struct {
+	Skin  image.Image
+	Style HeadStyle
+
+	// Slim selects the model with slim arms for the body style.
+	Slim bool
+}
+
+// checkSkin checks that the skin has the dimensions of a modern or legacy skin.
+func checkSkin(skin image.Image) error {
+	size := skin.Bounds().Size()
+	if size.X != 64 || (size.Y != 64 && size.Y != 32) {
+		return fmt.Errorf("%w, not %dx%d", ErrInvalidSkin, size.X, size.Y)
+	}
+	return nil
+}
+
+// RenderHead renders the head of the given skin with its hat overlay as icon.
+// Flat heads show the face only, isometric heads are rendered like blocks with the face at the front.
+func RenderHead(head Head) (image.Image, error) {
+	if err := checkSkin(head.Skin); err != nil {
+		return nil, err
+	}
+
+	icon := image.NewNRGBA(image.Rect(0, 0, assets.IconBounds.Dx(), assets.IconBounds.Dy()))
+	switch head.Style {
+	case "", HeadFlat:
+		face := headFace(head.Skin, skinHeadFront)
+		xdraw.NearestNeighbor.Scale(icon, icon.Bounds(), face, face.Bounds(), draw.Over, nil)
+	case HeadIsometric:
+		draw.Draw(icon, icon.Bounds(), RenderBlock(BlockFaces{
+			Top:   rotateClockwise(headFace(head.Skin, skinHeadTop)),
+			Front: headFace(head.Skin, skinHeadFront),
+			Side:  headFace(head.Skin, skinHeadLeft),
+		}, icon.Bounds().Dx()), image.Point{}, draw.Over)
+	case HeadBody:
+		model := PlayerClassic
+		if head.Slim {
+			model = PlayerSlim
+		}
+		body, err := RenderPlayer(PlayerOptions{Skin: head.Skin, Model: model, Scale: 1})
+		if err != nil {
+			return nil, err
+		}
+		drawFitted(icon, body)
+	default:
+		return nil, fmt.Errorf("%w, not %q", ErrInvalidHeadStyle, head.Style)
+	}
+	return icon, nil
+}
+
+// headFace returns the face of the head at the given position of the skin, covered by the hat overlay.
+func headFace(skin image.Image, position image.Point) *image.NRGBA {
+	origin := skin.Bounds().Min.Add(position)
+	face := image.NewNRGBA(image.Rect(0, 0, skinHeadSize, skinHeadSize))
+	draw.Draw(face, face.Bounds(), skin, origin, draw.Src)
+	draw.Draw(face, face.Bounds(), skin, origin.Add(skinHatOffset), draw.Over)
+	return face
+}
+
+// rotateClockwise rotates the given square image by 90 degrees clockwise.
+// The top of a head is rotated so its edge above the face meets the face on the rendered block.
+func rotateClockwise(img *image.NRGBA) *image.NRGBA {
+	size := img.Bounds().Dx()
+	rotated := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			rotated.SetNRGBA(x, y, img.NRGBAAt(y, size-1-x))
+		}
+	}
+	return rotated
+}
+
+// drawFitted scales the image to fit into the icon, keeping its aspect ratio, and centres it.
+func drawFitted(icon *image.NRGBA, img image.Image) {
+	size, bounds := img.Bounds().Size(), icon.Bounds()
+	scale := min(float64(bounds.Dx())/float64(size.X), float64(bounds.Dy())/float64(size.Y))
+	fitted := image.Pt(int(float64(size.X)*scale), int(float64(size.Y)*scale))
+	target := image.Rectangle{Max: fitted}.Add(bounds.Min.Add(bounds.Size().Sub(fitted).Div(2)))
+	xdraw.NearestNeighbor.Scale(icon, target, img, img.Bounds(), draw.Over, nil)
+}
@@ -0,0 +1,296 @@
+package generator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/menzerath/mcgen/assets"
+	"golang.org/x/image/font"
+)
+
+// settings of the enchantment glint
+const (
+	glintStrength = 0.75
+	glintFrames   = 16
+	glintDelay    = 10 // in 1/100 seconds
+)
+
+// limits of item stack decorations
+const (
+	MaxCount      = 99
+	MaxDurability = 100
+)
+
+// layout of item stack decorations, in pixels of the game's GUI which are doubled for the toast's icon
+const (
+	iconScale        = 2
+	countRight       = 17
+	countBaseline    = 16
+	durabilityX      = 2
+	durabilityY      = 13
+	durabilityWidth  = 13
+	durabilityHeight = 2
+)
+
+// list of errors returned when modifying icons
+var (
+	ErrNotTintable  = fmt.Errorf("icon has no tint layers")
+	ErrTooManyTints = fmt.Errorf("more tints than tint layers")
+	ErrInvalidColor = fmt.Errorf("invalid color")
+	ErrMissingFrame = fmt.Errorf("missing frame")
+
+	ErrInvalidCount      = fmt.Errorf("count must be between 1 and %d", MaxCount)
+	ErrInvalidDurability = fmt.Errorf("durability must be between 0 and %d", MaxDurability)
+)
+
+// countShadow is the colour of the count's shadow drawn by drawLine, a quarter of its white; animations keep it exactly in their palette.
+var countShadow = color.RGBA{R: 63, G: 63, B: 63, A: 255}
+
+// IconModifiers change the icon of an achievement like the game does for enchanted or dyed items.
+type IconModifiers struct {
+	// Enchanted adds the enchantment glint.
+	Enchanted bool
+
+	// Tints recolour the icon's tint layers declared in assets.TintLayers in order; nil keeps a layer's colour.
+	Tints []color.Color
+
+	// Count is the size of the item stack shown in the icon's corner; like in the game, it is hidden for 0 and 1.
+	Count int
+
+	// Durability is the remaining durability of a tool in percent, shown as bar below the icon unless it is nil or undamaged.
+	Durability *int
+}
+
+// IsZero reports whether the modifiers leave the icon unchanged.
+func (modifiers IconModifiers) IsZero() bool {
+	return !modifiers.Enchanted && len(modifiers.Tints) == 0 && modifiers.Count == 0 && modifiers.Durability == nil
+}
+
+// ParseHexColor parses a color written like "#ffff00" or "#ffff0080"; the leading "#" is optional.
+func ParseHexColor(text string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(text, "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 {
+		return color.RGBA{}, fmt.Errorf("%w %q", ErrInvalidColor, text)
+	}
+	return color.RGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}, nil
+}
+
+// checkIconModifiers checks that the modifiers can be applied to the background with the given name.
+func checkIconModifiers(name string, modifiers IconModifiers) error {
+	if modifiers.Count < 0 || modifiers.Count > MaxCount {
+		return fmt.Errorf("%w: %d", ErrInvalidCount, modifiers.Count)
+	}
+	if modifiers.Durability != nil && (*modifiers.Durability < 0 || *modifiers.Durability > MaxDurability) {
+		return fmt.Errorf("%w: %d", ErrInvalidDurability, *modifiers.Durability)
+	}
+	if len(modifiers.Tints) == 0 {
+		return nil
+	}
+	layers, exists := assets.TintLayers[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrNotTintable, name)
+	}
+	if len(modifiers.Tints) > len(layers) {
+		return fmt.Errorf("%w: %s has %d", ErrTooManyTints, name, len(layers))
+	}
+	return nil
+}
+
+// modifyIcon returns a copy of the given background with the modifiers applied to its icon.
+// The icon's pixels are told apart from the frame by comparing them with the frame the background is based on.
+// The glint is moved according to the given frame of its animation.
+// The stack's count is not drawn, as it requires the font; see drawCount.
+func (generator *Generator) modifyIcon(background image.Image, name string, modifiers IconModifiers, frame int) (image.Image, error) {
+	if err := checkIconModifiers(name, modifiers); err != nil {
+		return nil, err
+	}
+	empty, exists := generator.Assets.FrameFor(name)
+	if !exists {
+		return nil, fmt.Errorf("%w for %s", ErrMissingFrame, name)
+	}
+
+	result := image.NewNRGBA(background.Bounds())
+	draw.Draw(result, result.Bounds(), background, background.Bounds().Min, draw.Src)
+
+	// collect the icon's pixels once, as both modifiers only change those
+	var icon []image.Point
+	for y := assets.IconBounds.Min.Y; y < assets.IconBounds.Max.Y; y++ {
+		for x := assets.IconBounds.Min.X; x < assets.IconBounds.Max.X; x++ {
+			point := result.Bounds().Min.Add(image.Pt(x, y))
+			framePoint := empty.Bounds().Min.Add(image.Pt(x, y))
+			if color.NRGBAModel.Convert(empty.At(framePoint.X, framePoint.Y)) != result.NRGBAAt(point.X, point.Y) {
+				icon = append(icon, point)
+			}
+		}
+	}
+
+	for i, tint := range modifiers.Tints {
+		if tint != nil {
+			applyTint(result, icon, assets.TintLayers[name][i], tint)
+		}
+	}
+	if modifiers.Enchanted {
+		applyGlint(result, icon, generator.glint, frame)
+	}
+	if modifiers.Durability != nil && *modifiers.Durability < MaxDurability {
+		drawDurability(result, *modifiers.Durability)
+	}
+	return result, nil
+}
+
+// drawDurability draws the durability bar below the icon like the game does for damaged tools.
+// Its length and colour, fading from green to red, show the remaining durability.
+func drawDurability(img *image.NRGBA, durability int) {
+	origin := img.Bounds().Min.Add(assets.IconBounds.Min).Add(image.Pt(durabilityX*iconScale, durabilityY*iconScale))
+	background := image.Rect(0, 0, durabilityWidth*iconScale, durabilityHeight*iconScale).Add(origin)
+	draw.Draw(img, background, image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	fraction := float64(durability) / MaxDurability
+	width := int(math.Round(durabilityWidth*fraction)) * iconScale
+	bar := image.Rect(0, 0, width, iconScale).Add(origin)
+	draw.Draw(img, bar, image.NewUniform(hsvColor(fraction*120, 1, 1)), image.Point{}, draw.Src)
+}
+
+// drawCount draws the count of an item stack at the bottom right corner of the icon at the given position, in white with shadow like the game.
+// As freetype is not thread-safe, the caller must hold the generator's imageWritingLock.
+func drawCount(img draw.Image, face font.Face, count int, icon image.Point) {
+	if count <= 1 {
+		return
+	}
+	spans := []TextSpan{{Text: strconv.Itoa(count), Style: TextStyle{Color: color.White}}}
+	x := icon.X + countRight*iconScale - lineWidth(face, spans)
+	drawLine(img, face, spans, image.Pt(x, icon.Y+countBaseline*iconScale), true)
+}
+
+// applyTint recolours the given pixels selected by the layer, keeping their relative brightness.
+func applyTint(img *image.NRGBA, pixels []image.Point, layer assets.TintLayer, tint color.Color) {
+	var selected []image.Point
+	brightest := 0.0
+	for _, point := range pixels {
+		c := img.NRGBAAt(point.X, point.Y)
+		hue, saturation, value := hsv(c)
+		if layer.MaxValue > 0 {
+			if value > layer.MaxValue {
+				continue
+			}
+		} else if saturation < layer.MinSaturation || !hueWithin(hue, layer.MinHue, layer.MaxHue) {
+			continue
+		}
+		selected = append(selected, point)
+		brightest = max(brightest, luminance(c))
+	}
+
+	t := color.NRGBAModel.Convert(tint).(color.NRGBA)
+	for _, point := range selected {
+		c := img.NRGBAAt(point.X, point.Y)
+
+		// layers without any brightness, like black spots, are filled with the tint
+		factor := 1.0
+		if brightest > 0 {
+			factor = luminance(c) / brightest
+		}
+		img.SetNRGBA(point.X, point.Y, color.NRGBA{
+			R: uint8(float64(t.R) * factor),
+			G: uint8(float64(t.G) * factor),
+			B: uint8(float64(t.B) * factor),
+			A: c.A,
+		})
+	}
+}
+
+// applyGlint adds the glint texture to the given pixels, moved diagonally according to the frame of its animation.
+func applyGlint(img *image.NRGBA, pixels []image.Point, glint image.Image, frame int) {
+	size := glint.Bounds().Size()
+	offset := frame * size.X / glintFrames
+
+	for _, point := range pixels {
+		g := color.NRGBAModel.Convert(glint.At(
+			glint.Bounds().Min.X+mod(point.X-offset, size.X),
+			glint.Bounds().Min.Y+mod(point.Y, size.Y),
+		)).(color.NRGBA)
+		c := img.NRGBAAt(point.X, point.Y)
+		img.SetNRGBA(point.X, point.Y, color.NRGBA{
+			R: addClamped(c.R, float64(g.R)*glintStrength),
+			G: addClamped(c.G, float64(g.G)*glintStrength),
+			B: addClamped(c.B, float64(g.B)*glintStrength),
+			A: c.A,
+		})
+	}
+}
+
+// hsv returns the hue in degrees, saturation and value of the given color.
+func hsv(c color.NRGBA) (float64, float64, float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	maximum := max(r, g, b)
+	delta := maximum - min(r, g, b)
+	if maximum == 0 || delta == 0 {
+		return 0, 0, maximum
+	}
+
+	var hue float64
+	switch maximum {
+	case r:
+		hue = math.Mod((g-b)/delta, 6)
+	case g:
+		hue = (b-r)/delta + 2
+	default:
+		hue = (r-g)/delta + 4
+	}
+	hue *= 60
+	if hue < 0 {
+		hue += 360
+	}
+	return hue, delta / maximum, maximum
+}
+
+// hueWithin reports whether the hue is between minimum and maximum, which wrap around if minimum is greater.
+func hueWithin(hue float64, minimum float64, maximum float64) bool {
+	if minimum <= maximum {
+		return hue >= minimum && hue <= maximum
+	}
+	return hue >= minimum || hue <= maximum
+}
+
+// hsvColor returns the color of the given hue in degrees, saturation and value.
+func hsvColor(hue float64, saturation float64, value float64) color.NRGBA {
+	chroma := value * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g = chroma, x
+	case hue < 120:
+		r, g = x, chroma
+	case hue < 180:
+		g, b = chroma, x
+	case hue < 240:
+		g, b = x, chroma
+	case hue < 300:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	m := value - chroma
+	return color.NRGBA{R: uint8(math.Round((r + m) * 255)), G: uint8(math.Round((g + m) * 255)), B: uint8(math.Round((b + m) * 255)), A: 255}
+}
+
+func luminance(c color.NRGBA) float64 {
+	return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+}
+
+func addClamped(value uint8, addition float64) uint8 {
+	return uint8(min(float64(value)+addition, 255))
+}
+
+func mod(a int, b int) int {
+	return ((a % b) + b) % b
+}
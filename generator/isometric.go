@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Brightness of a block's faces, resembling the game's lighting of blocks in the inventory.
+const (
+	blockTopShade   = 1.0
+	blockLeftShade  = 0.8
+	blockRightShade = 0.6
+)
+
+// Proportions of a block seen like in the inventory, rotated by 45° and tilted by 30°, relative to its width.
+var (
+	blockTopHeight  = 0.5
+	blockEdgeHeight = math.Cos(math.Pi/6) / math.Sqrt2
+)
+
+// BlockFaces contains the textures of a block's visible faces.
+// Textures may have any size; they are stretched to the faces.
+type BlockFaces struct {
+	Top   image.Image
+	Front image.Image
+	Side  image.Image
+}
+
+// blockFace is a parallelogram of a rendered block showing a texture.
+type blockFace struct {
+	texture image.Image
+	shade   float64
+	origin  [2]float64
+	u, v    [2]float64
+}
+
+// RenderBlock renders the given faces as isometric block fitting into a square of the given size, like the game shows blocks in the inventory.
+// The front is shown on the left and the side on the right, both darker than the top.
+func RenderBlock(faces BlockFaces, size int) *image.NRGBA {
+	// fit the block's height into the square and center it horizontally
+	width := float64(size) / (blockTopHeight + blockEdgeHeight)
+	top := blockTopHeight * width
+	edge := blockEdgeHeight * width
+	left := (float64(size) - width) / 2
+
+	leftCorner := [2]float64{left, top / 2}
+	topCorner := [2]float64{left + width/2, 0}
+	rightCorner := [2]float64{left + width, top / 2}
+	frontCorner := [2]float64{left + width/2, top}
+
+	parts := []blockFace{
+		{texture: faces.Top, shade: blockTopShade, origin: leftCorner, u: sub(topCorner, leftCorner), v: sub(frontCorner, leftCorner)},
+		{texture: faces.Front, shade: blockLeftShade, origin: leftCorner, u: sub(frontCorner, leftCorner), v: [2]float64{0, edge}},
+		{texture: faces.Side, shade: blockRightShade, origin: frontCorner, u: sub(rightCorner, frontCorner), v: [2]float64{0, edge}},
+	}
+
+	block := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			point := [2]float64{float64(x) + 0.5, float64(y) + 0.5}
+			for _, part := range parts {
+				if c, inside := part.sample(point); inside {
+					block.SetNRGBA(x, y, c)
+					break
+				}
+			}
+		}
+	}
+	return block
+}
+
+// sample returns the shaded color of the face's texture at the given point, if the point is within the face.
+func (face blockFace) sample(point [2]float64) (color.NRGBA, bool) {
+	if face.texture == nil {
+		return color.NRGBA{}, false
+	}
+	s, t, inside := face.coordinates(point)
+	if !inside {
+		return color.NRGBA{}, false
+	}
+
+	bounds := face.texture.Bounds()
+	c := color.NRGBAModel.Convert(face.texture.At(
+		bounds.Min.X+int(s*float64(bounds.Dx())),
+		bounds.Min.Y+int(t*float64(bounds.Dy())),
+	)).(color.NRGBA)
+	c.R = uint8(float64(c.R) * face.shade)
+	c.G = uint8(float64(c.G) * face.shade)
+	c.B = uint8(float64(c.B) * face.shade)
+	return c, true
+}
+
+// coordinates returns the texture coordinates s and t of the given point, both between 0 and 1 if the point is within the face.
+func (face blockFace) coordinates(point [2]float64) (float64, float64, bool) {
+	// solve point = origin + s*u + t*v for the texture coordinates s and t
+	p := sub(point, face.origin)
+	determinant := face.u[0]*face.v[1] - face.u[1]*face.v[0]
+	if determinant == 0 {
+		return 0, 0, false
+	}
+	s := (p[0]*face.v[1] - p[1]*face.v[0]) / determinant
+	t := (face.u[0]*p[1] - face.u[1]*p[0]) / determinant
+	return s, t, s >= 0 && s < 1 && t >= 0 && t < 1
+}
+
+func sub(a [2]float64, b [2]float64) [2]float64 {
+	return [2]float64{a[0] - b[0], a[1] - b[1]}
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/menzerath/mcgen/metrics"
+)
+
+// list of errors returned when the generator is overloaded
+var (
+	ErrOverloaded = fmt.Errorf("too many concurrent generations")
+)
+
+// ConcurrencyLimits restrict how many images are generated at the same time.
+type ConcurrencyLimits struct {
+	// MaxConcurrent is the maximum number of images generated at the same time; zero means unlimited.
+	MaxConcurrent int
+
+	// MaxQueued is the maximum number of generations waiting for a free slot.
+	// Further generations fail immediately.
+	MaxQueued int
+
+	// QueueTimeout is the maximum duration a generation waits for a free slot; zero means no timeout.
+	QueueTimeout time.Duration
+}
+
+// limiter is a semaphore with a bounded queue of waiting callers.
+type limiter struct {
+	limits ConcurrencyLimits
+	slots  chan struct{}
+	queued atomic.Int64
+}
+
+// newLimiter returns a limiter enforcing the given limits.
+func newLimiter(limits ConcurrencyLimits) *limiter {
+	limiter := &limiter{
+		limits: limits,
+	}
+	if limits.MaxConcurrent > 0 {
+		limiter.slots = make(chan struct{}, limits.MaxConcurrent)
+	}
+	return limiter
+}
+
+// acquire waits for a free slot and returns a function to release it again.
+// It fails with ErrOverloaded if the queue is full or the queue timeout passed, and with the context's error if it is cancelled.
+func (limiter *limiter) acquire(ctx context.Context) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if limiter.slots == nil {
+		return limiter.track(), nil
+	}
+
+	// take a free slot right away if possible
+	select {
+	case limiter.slots <- struct{}{}:
+		return limiter.track(), nil
+	default:
+	}
+
+	// otherwise wait in the queue, if there is still room
+	if limiter.queued.Add(1) > int64(limiter.limits.MaxQueued) {
+		limiter.queued.Add(-1)
+		return nil, ErrOverloaded
+	}
+	metrics.GeneratorQueued.Inc()
+	defer func() {
+		limiter.queued.Add(-1)
+		metrics.GeneratorQueued.Dec()
+	}()
+
+	var timeout <-chan time.Time
+	if limiter.limits.QueueTimeout > 0 {
+		timer := time.NewTimer(limiter.limits.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case limiter.slots <- struct{}{}:
+		return limiter.track(), nil
+	case <-timeout:
+		return nil, fmt.Errorf("%w: no free slot within %s", ErrOverloaded, limiter.limits.QueueTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// track counts an acquired slot as in-flight and returns the function to release it.
+func (limiter *limiter) track() func() {
+	metrics.GeneratorInFlight.Inc()
+	return func() {
+		metrics.GeneratorInFlight.Dec()
+		if limiter.slots != nil {
+			<-limiter.slots
+		}
+	}
+}
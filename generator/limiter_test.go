@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterAcquire(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	shortly, cancelShortly := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShortly()
+
+	tests := []struct {
+		name   string
+		limits ConcurrencyLimits
+		ctx    context.Context
+		held   int // slots acquired before
+		err    error
+	}{
+		{"unlimited", ConcurrencyLimits{}, context.Background(), 100, nil},
+		{"free slot", ConcurrencyLimits{MaxConcurrent: 2}, context.Background(), 1, nil},
+		{"cancelled before", ConcurrencyLimits{}, cancelled, 0, context.Canceled},
+		{"no queue", ConcurrencyLimits{MaxConcurrent: 1}, context.Background(), 1, ErrOverloaded},
+		{"queue timeout", ConcurrencyLimits{MaxConcurrent: 1, MaxQueued: 1, QueueTimeout: 10 * time.Millisecond}, context.Background(), 1, ErrOverloaded},
+		{"context deadline while queued", ConcurrencyLimits{MaxConcurrent: 1, MaxQueued: 1}, shortly, 1, context.DeadlineExceeded},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			limiter := newLimiter(test.limits)
+			for range test.held {
+				release, err := limiter.acquire(context.Background())
+				if err != nil {
+					t.Fatalf("acquiring a held slot returned %v", err)
+				}
+				defer release()
+			}
+
+			release, err := limiter.acquire(test.ctx)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("acquire returned %v, want %v", err, test.err)
+			}
+			if err == nil {
+				release()
+			}
+			if queued := limiter.queued.Load(); queued != 0 {
+				t.Errorf("%d callers still queued", queued)
+			}
+		})
+	}
+}
+
+func TestLimiterQueue(t *testing.T) {
+	limiter := newLimiter(ConcurrencyLimits{MaxConcurrent: 1, MaxQueued: 1})
+	release, err := limiter.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire returned %v", err)
+	}
+
+	// the queued caller gets the slot as soon as it is released
+	acquired := make(chan error)
+	go func() {
+		release, err := limiter.acquire(context.Background())
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+	for limiter.queued.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the queue is full now
+	if _, err := limiter.acquire(context.Background()); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("acquire with a full queue returned %v, want %v", err, ErrOverloaded)
+	}
+
+	release()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("queued acquire returned %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued caller did not get the released slot")
+	}
+}
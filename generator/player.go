@@ -0,0 +1,439 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// PlayerModel selects the arm width of a skin.
+type PlayerModel string
+
+// PlayerModel constants.
+const (
+	PlayerClassic PlayerModel = "classic"
+	PlayerSlim    PlayerModel = "slim"
+)
+
+// PlayerView selects the direction a player is seen from.
+type PlayerView string
+
+// PlayerView constants.
+const (
+	PlayerFront     PlayerView = "front"
+	PlayerIsometric PlayerView = "isometric"
+)
+
+// PlayerPose selects how a player's limbs are posed.
+type PlayerPose string
+
+// PlayerPose constants.
+const (
+	PlayerStanding PlayerPose = "standing"
+	PlayerWalking  PlayerPose = "walking"
+	PlayerWaving   PlayerPose = "waving"
+)
+
+// limits of rendered players
+const (
+	DefaultPlayerScale = 8
+	MaxPlayerScale     = 32
+)
+
+// list of errors returned when rendering players
+var (
+	ErrInvalidPlayerModel = fmt.Errorf("model must be classic or slim")
+	ErrInvalidPlayerView  = fmt.Errorf("view must be front or isometric")
+	ErrInvalidPlayerPose  = fmt.Errorf("pose must be standing, walking or waving")
+	ErrInvalidPlayerScale = fmt.Errorf("scale must be between 1 and %d", MaxPlayerScale)
+)
+
+// PlayerOptions describe how to render a player.
+// Empty values select a classic model standing in front view at the default scale.
+type PlayerOptions struct {
+	Skin  image.Image
+	Model PlayerModel
+	View  PlayerView
+	Pose  PlayerPose
+
+	// Scale is the number of pixels per pixel of the skin.
+	Scale int
+}
+
+// withDefaults returns the options with all empty values replaced by their defaults.
+func (options PlayerOptions) withDefaults() PlayerOptions {
+	if options.Model == "" {
+		options.Model = PlayerClassic
+	}
+	if options.View == "" {
+		options.View = PlayerFront
+	}
+	if options.Pose == "" {
+		options.Pose = PlayerStanding
+	}
+	if options.Scale == 0 {
+		options.Scale = DefaultPlayerScale
+	}
+	return options
+}
+
+// validate checks all options, which must have their defaults applied.
+func (options PlayerOptions) validate() error {
+	if err := checkSkin(options.Skin); err != nil {
+		return err
+	}
+	if options.Model != PlayerClassic && options.Model != PlayerSlim {
+		return fmt.Errorf("%w, not %q", ErrInvalidPlayerModel, options.Model)
+	}
+	if options.View != PlayerFront && options.View != PlayerIsometric {
+		return fmt.Errorf("%w, not %q", ErrInvalidPlayerView, options.View)
+	}
+	if options.Pose != PlayerStanding && options.Pose != PlayerWalking && options.Pose != PlayerWaving {
+		return fmt.Errorf("%w, not %q", ErrInvalidPlayerPose, options.Pose)
+	}
+	if options.Scale < 1 || options.Scale > MaxPlayerScale {
+		return fmt.Errorf("%w: %d", ErrInvalidPlayerScale, options.Scale)
+	}
+	return nil
+}
+
+// Inflation of the overlay layers around the base layer, in pixels of the skin.
+const (
+	hatInflation     = 0.5
+	overlayInflation = 0.25
+)
+
+// Rotations of the isometric view, in degrees; the player's front faces the bottom left like blocks in the inventory.
+const (
+	isometricYaw   = -45
+	isometricPitch = -30
+)
+
+// GeneratePlayer renders a player and returns it as PNG.
+// It will return an error if the options are invalid, the generator is overloaded or the context is cancelled while waiting.
+func (generator *Generator) GeneratePlayer(ctx context.Context, options PlayerOptions) ([]byte, error) {
+	release, err := generator.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	player, err := RenderPlayer(options)
+	if err != nil {
+		return nil, err
+	}
+	return encodePNG(player)
+}
+
+// RenderPlayer renders the full body of a player wearing the given skin, including its overlay layers.
+// The image is transparent around the player and just large enough to contain it, so it can be placed into any layout.
+// Legacy skins of 64x32 pixels are converted like the game does, using mirrored right limbs as left ones.
+func RenderPlayer(options PlayerOptions) (*image.NRGBA, error) {
+	options = options.withDefaults()
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	skin := modernSkin(options.Skin)
+
+	armWidth := 4.0
+	if options.Model == PlayerSlim {
+		armWidth = 3
+	}
+	rightArm, leftArm, rightLeg, leftLeg := poseRotations(options.Pose)
+
+	// the model is placed in pixels of the skin with x to the right of the viewer, y down and z towards the viewer
+	// the base layer is drawn first, so the overlay layers can be blended onto it
+	parts := []modelPart{
+		{size: vec3{8, 8, 8}, position: vec3{0, -8, -2}, uv: image.Pt(0, 0)},
+		{size: vec3{8, 12, 4}, position: vec3{0, 0, 0}, uv: image.Pt(16, 16)},
+		{size: vec3{armWidth, 12, 4}, position: vec3{-armWidth, 0, 0}, uv: image.Pt(40, 16), pivot: vec3{-armWidth / 2, 2, 2}, rotation: rightArm},
+		{size: vec3{armWidth, 12, 4}, position: vec3{8, 0, 0}, uv: image.Pt(32, 48), pivot: vec3{8 + armWidth/2, 2, 2}, rotation: leftArm},
+		{size: vec3{4, 12, 4}, position: vec3{0, 12, 0}, uv: image.Pt(0, 16), pivot: vec3{2, 12, 2}, rotation: rightLeg},
+		{size: vec3{4, 12, 4}, position: vec3{4, 12, 0}, uv: image.Pt(16, 48), pivot: vec3{6, 12, 2}, rotation: leftLeg},
+	}
+	overlays := []image.Point{image.Pt(32, 0), image.Pt(16, 32), image.Pt(40, 32), image.Pt(48, 48), image.Pt(0, 32), image.Pt(0, 48)}
+	for i, uv := range overlays {
+		overlay := parts[i]
+		overlay.uv = uv
+		overlay.overlay = true
+		overlay.inflation = overlayInflation
+		if i == 0 {
+			overlay.inflation = hatInflation
+		}
+		parts = append(parts, overlay)
+	}
+
+	view := identity()
+	if options.View == PlayerIsometric {
+		view = rotationX(isometricPitch).mul(rotationY(isometricYaw))
+	}
+
+	var faces []modelFace
+	for _, part := range parts {
+		faces = append(faces, part.faces(view)...)
+	}
+	return rasterize(skin, faces, float64(options.Scale), options.View), nil
+}
+
+// poseRotations returns the rotations of the right arm, left arm, right leg and left leg for the given pose.
+func poseRotations(pose PlayerPose) (matrix, matrix, matrix, matrix) {
+	switch pose {
+	case PlayerWalking:
+		return rotationX(-30), rotationX(30), rotationX(30), rotationX(-30)
+	case PlayerWaving:
+		return rotationZ(150).mul(rotationX(-10)), rotationX(5), identity(), identity()
+	default:
+		return identity(), identity(), identity(), identity()
+	}
+}
+
+// modernSkin converts a legacy skin of 64x32 pixels into a modern one by mirroring the right limbs.
+func modernSkin(skin image.Image) image.Image {
+	bounds := skin.Bounds()
+	if bounds.Dy() != 32 {
+		return skin
+	}
+
+	modern := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	draw.Draw(modern, image.Rect(0, 0, 64, 32), skin, bounds.Min, draw.Src)
+	for _, limb := range []struct{ from, to image.Point }{
+		{image.Pt(0, 16), image.Pt(16, 48)},  // leg
+		{image.Pt(40, 16), image.Pt(32, 48)}, // arm
+	} {
+		// each face is mirrored, which swaps the sides of the limb
+		for _, face := range [][2]image.Rectangle{
+			{image.Rect(4, 0, 8, 4), image.Rect(4, 0, 8, 4)},
+			{image.Rect(8, 0, 12, 4), image.Rect(8, 0, 12, 4)},
+			{image.Rect(0, 4, 4, 16), image.Rect(8, 4, 12, 16)},
+			{image.Rect(4, 4, 8, 16), image.Rect(4, 4, 8, 16)},
+			{image.Rect(8, 4, 12, 16), image.Rect(0, 4, 4, 16)},
+			{image.Rect(12, 4, 16, 16), image.Rect(12, 4, 16, 16)},
+		} {
+			from, to := face[0].Add(limb.from).Add(bounds.Min), face[1].Add(limb.to)
+			for y := 0; y < from.Dy(); y++ {
+				for x := 0; x < from.Dx(); x++ {
+					modern.Set(to.Max.X-1-x, to.Min.Y+y, skin.At(from.Min.X+x, from.Min.Y+y))
+				}
+			}
+		}
+	}
+	return modern
+}
+
+// A modelPart is a box of the player model, textured by the box-shaped region of the skin at uv.
+type modelPart struct {
+	size      vec3
+	position  vec3
+	uv        image.Point
+	inflation float64
+	overlay   bool
+
+	// rotation turns the part around its pivot; the zero matrix leaves it unchanged.
+	pivot    vec3
+	rotation matrix
+}
+
+// A modelFace is a transformed rectangle of a model part with the region of the skin shown on it.
+type modelFace struct {
+	origin  vec3
+	u, v    vec3
+	normal  vec3
+	texture image.Rectangle
+	overlay bool
+}
+
+// faces returns the faces of the part transformed by its rotation and the given view.
+// Faces turned away from the viewer are left out.
+func (part modelPart) faces(view matrix) []modelFace {
+	rotation := part.rotation
+	if rotation == (matrix{}) {
+		rotation = identity()
+	}
+	transform := func(point vec3) vec3 {
+		return view.apply(rotation.apply(point.sub(part.pivot)).add(part.pivot))
+	}
+
+	// all faces are given by a corner, where the texture starts, and the edges along the texture's x and y axes
+	w, h, d := part.size[0], part.size[1], part.size[2]
+	inflated := vec3{w + 2*part.inflation, h + 2*part.inflation, d + 2*part.inflation}
+	low := part.position.sub(vec3{part.inflation, part.inflation, part.inflation})
+	high := low.add(inflated)
+	width, height, depth := int(w), int(h), int(d)
+	u, v := part.uv.X, part.uv.Y
+	definitions := []struct {
+		origin vec3
+		u, v   vec3
+		normal vec3
+		uv     image.Rectangle
+	}{
+		{vec3{low[0], low[1], high[2]}, vec3{inflated[0], 0, 0}, vec3{0, inflated[1], 0}, vec3{0, 0, 1}, image.Rect(u+depth, v+depth, u+depth+width, v+depth+height)},
+		{vec3{high[0], low[1], low[2]}, vec3{-inflated[0], 0, 0}, vec3{0, inflated[1], 0}, vec3{0, 0, -1}, image.Rect(u+2*depth+width, v+depth, u+2*depth+2*width, v+depth+height)},
+		{vec3{low[0], low[1], low[2]}, vec3{0, 0, inflated[2]}, vec3{0, inflated[1], 0}, vec3{-1, 0, 0}, image.Rect(u, v+depth, u+depth, v+depth+height)},
+		{vec3{high[0], low[1], high[2]}, vec3{0, 0, -inflated[2]}, vec3{0, inflated[1], 0}, vec3{1, 0, 0}, image.Rect(u+depth+width, v+depth, u+2*depth+width, v+depth+height)},
+		{vec3{low[0], low[1], low[2]}, vec3{inflated[0], 0, 0}, vec3{0, 0, inflated[2]}, vec3{0, -1, 0}, image.Rect(u+depth, v, u+depth+width, v+depth)},
+		{vec3{low[0], high[1], high[2]}, vec3{inflated[0], 0, 0}, vec3{0, 0, -inflated[2]}, vec3{0, 1, 0}, image.Rect(u+depth+width, v, u+depth+2*width, v+depth)},
+	}
+
+	faces := make([]modelFace, 0, len(definitions))
+	for _, definition := range definitions {
+		origin := transform(definition.origin)
+		normal := view.apply(rotation.apply(definition.normal))
+		if normal[2] <= 0 {
+			continue
+		}
+		faces = append(faces, modelFace{
+			origin:  origin,
+			u:       transform(definition.origin.add(definition.u)).sub(origin),
+			v:       transform(definition.origin.add(definition.v)).sub(origin),
+			normal:  normal,
+			texture: definition.uv,
+			overlay: part.overlay,
+		})
+	}
+	return faces
+}
+
+// shade returns the brightness of the face, resembling the lighting of blocks.
+// Faces looking straight at the viewer of the front view are not darkened.
+func (face modelFace) shade(view PlayerView) float64 {
+	switch {
+	case view == PlayerFront && face.normal[2] > 0.8:
+		return blockTopShade
+	case face.normal[1] < -0.3:
+		return blockTopShade
+	case face.normal[0] <= 0:
+		return blockLeftShade
+	default:
+		return blockRightShade
+	}
+}
+
+// rasterize draws the faces at the given scale into an image just large enough to contain them.
+// A depth buffer keeps the nearest face of each pixel; overlay faces are blended onto what is behind them.
+func rasterize(skin image.Image, faces []modelFace, scale float64, view PlayerView) *image.NRGBA {
+	minimum := vec3{math.Inf(1), math.Inf(1)}
+	maximum := vec3{math.Inf(-1), math.Inf(-1)}
+	for _, face := range faces {
+		for _, corner := range []vec3{face.origin, face.origin.add(face.u), face.origin.add(face.v), face.origin.add(face.u).add(face.v)} {
+			minimum = vec3{min(minimum[0], corner[0]), min(minimum[1], corner[1])}
+			maximum = vec3{max(maximum[0], corner[0]), max(maximum[1], corner[1])}
+		}
+	}
+	bounds := image.Rect(0, 0, int(math.Ceil((maximum[0]-minimum[0])*scale)), int(math.Ceil((maximum[1]-minimum[1])*scale)))
+	img := image.NewNRGBA(bounds)
+	depths := make([]float64, bounds.Dx()*bounds.Dy())
+	for i := range depths {
+		depths[i] = math.Inf(-1)
+	}
+
+	for _, face := range faces {
+		shade := face.shade(view)
+		part := blockFace{
+			origin: [2]float64{(face.origin[0] - minimum[0]) * scale, (face.origin[1] - minimum[1]) * scale},
+			u:      [2]float64{face.u[0] * scale, face.u[1] * scale},
+			v:      [2]float64{face.v[0] * scale, face.v[1] * scale},
+		}
+
+		// only visit the pixels within the face's bounding box
+		area := image.Rect(
+			int(math.Floor(min(part.origin[0], part.origin[0]+part.u[0], part.origin[0]+part.v[0], part.origin[0]+part.u[0]+part.v[0]))),
+			int(math.Floor(min(part.origin[1], part.origin[1]+part.u[1], part.origin[1]+part.v[1], part.origin[1]+part.u[1]+part.v[1]))),
+			int(math.Ceil(max(part.origin[0], part.origin[0]+part.u[0], part.origin[0]+part.v[0], part.origin[0]+part.u[0]+part.v[0]))),
+			int(math.Ceil(max(part.origin[1], part.origin[1]+part.u[1], part.origin[1]+part.v[1], part.origin[1]+part.u[1]+part.v[1]))),
+		).Intersect(bounds)
+		for y := area.Min.Y; y < area.Max.Y; y++ {
+			for x := area.Min.X; x < area.Max.X; x++ {
+				s, t, inside := part.coordinates([2]float64{float64(x) + 0.5, float64(y) + 0.5})
+				if !inside {
+					continue
+				}
+				depth := face.origin[2] + s*face.u[2] + t*face.v[2]
+				if depth <= depths[y*bounds.Dx()+x] {
+					continue
+				}
+
+				c := color.NRGBAModel.Convert(skin.At(
+					skin.Bounds().Min.X+face.texture.Min.X+int(s*float64(face.texture.Dx())),
+					skin.Bounds().Min.Y+face.texture.Min.Y+int(t*float64(face.texture.Dy())),
+				)).(color.NRGBA)
+				if !face.overlay {
+					// like in the game, the base layer is always opaque
+					c.A = 255
+				}
+				if c.A == 0 {
+					continue
+				}
+				c.R = uint8(float64(c.R) * shade)
+				c.G = uint8(float64(c.G) * shade)
+				c.B = uint8(float64(c.B) * shade)
+
+				depths[y*bounds.Dx()+x] = depth
+				if c.A == 255 {
+					img.SetNRGBA(x, y, c)
+				} else {
+					draw.Draw(img, image.Rect(x, y, x+1, y+1), image.NewUniform(c), image.Point{}, draw.Over)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// vec3 is a point or direction in the model's space.
+type vec3 [3]float64
+
+func (a vec3) add(b vec3) vec3 {
+	return vec3{a[0] + b[0], a[1] + b[1], a[2] + b[2]}
+}
+
+func (a vec3) sub(b vec3) vec3 {
+	return vec3{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+// matrix is a rotation in the model's space.
+type matrix [3][3]float64
+
+func identity() matrix {
+	return matrix{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+}
+
+// rotationX rotates around the x axis by the given degrees; positive angles swing hanging limbs forward.
+func rotationX(degrees float64) matrix {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	return matrix{{1, 0, 0}, {0, cos, -sin}, {0, sin, cos}}
+}
+
+// rotationY rotates around the y axis by the given degrees; negative angles turn the front to the left.
+func rotationY(degrees float64) matrix {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	return matrix{{cos, 0, sin}, {0, 1, 0}, {-sin, 0, cos}}
+}
+
+// rotationZ rotates around the z axis by the given degrees; positive angles lift hanging limbs to the left of the viewer.
+func rotationZ(degrees float64) matrix {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	return matrix{{cos, -sin, 0}, {sin, cos, 0}, {0, 0, 1}}
+}
+
+func (m matrix) mul(n matrix) matrix {
+	var result matrix
+	for i := range 3 {
+		for j := range 3 {
+			for k := range 3 {
+				result[i][j] += m[i][k] * n[k][j]
+			}
+		}
+	}
+	return result
+}
+
+func (m matrix) apply(a vec3) vec3 {
+	return vec3{
+		m[0][0]*a[0] + m[0][1]*a[1] + m[0][2]*a[2],
+		m[1][0]*a[0] + m[1][1]*a[1] + m[1][2]*a[2],
+		m[2][0]*a[0] + m[2][1]*a[1] + m[2][2]*a[2],
+	}
+}
@@ -0,0 +1,343 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/menzerath/mcgen/assets"
+)
+
+// RecipeType selects the GUI a recipe is shown in.
+type RecipeType string
+
+// RecipeType constants.
+const (
+	RecipeCrafting  RecipeType = "crafting"
+	RecipeInventory RecipeType = "inventory"
+	RecipeFurnace   RecipeType = "furnace"
+	RecipeSmithing  RecipeType = "smithing"
+)
+
+// list of errors returned when rendering recipes
+var (
+	ErrUnknownItem       = fmt.Errorf("unknown item")
+	ErrInvalidRecipeType = fmt.Errorf("recipe type must be crafting, inventory, furnace or smithing")
+	ErrInvalidRecipeGrid = fmt.Errorf("recipe grid does not fit")
+	ErrMissingResult     = fmt.Errorf("recipe has no result")
+)
+
+// recipeGrids are the maximum rows and columns of the inputs of each type of recipe.
+var recipeGrids = map[RecipeType]image.Point{
+	RecipeCrafting:  image.Pt(3, 3),
+	RecipeInventory: image.Pt(2, 2),
+	RecipeFurnace:   image.Pt(1, 1),
+	RecipeSmithing:  image.Pt(3, 1),
+}
+
+// layout of the recipe GUI, in pixels of the game's GUI which are doubled like the toast's icon
+const (
+	panelPadding   = 7
+	slotSize       = 18
+	largeSlotSize  = 26
+	itemSize       = 16
+	arrowWidth     = 22
+	arrowHeight    = 15
+	arrowGapBefore = 6
+	arrowGapAfter  = 8
+	flameSize      = 14
+)
+
+// colours of the game's GUI
+var (
+	guiBackground = color.RGBA{R: 198, G: 198, B: 198, A: 255}
+	guiHighlight  = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	guiShadow     = color.RGBA{R: 85, G: 85, B: 85, A: 255}
+	guiOutline    = color.RGBA{A: 255}
+	slotShadow    = color.RGBA{R: 55, G: 55, B: 55, A: 255}
+	slotFill      = color.RGBA{R: 139, G: 139, B: 139, A: 255}
+)
+
+// arrowSprite is the arrow pointing to the result; flameSprite is the burning fuel of a furnace.
+var (
+	arrowSprite = sprite([]string{
+		"...............#......",
+		"...............##.....",
+		"...............###....",
+		"...............####...",
+		"...............#####..",
+		"#####################.",
+		"######################",
+		"######################",
+		"######################",
+		"#####################.",
+		"...............#####..",
+		"...............####...",
+		"...............###....",
+		"...............##.....",
+		"...............#......",
+	}, map[byte]color.RGBA{'#': slotFill})
+	flameSprite = sprite([]string{
+		"......r.......",
+		"......r.......",
+		".....rr.......",
+		".....ror......",
+		"....roor...r..",
+		"...rooor..rr..",
+		"...royor.ror..",
+		"..rooyyrrorr..",
+		"..royyyoroor..",
+		".rooyyyyooor..",
+		".royyyyyyyor..",
+		".royyyyyyyor..",
+		"..royyyyyor...",
+		"...rrrrrrr....",
+	}, map[byte]color.RGBA{
+		'r': {R: 204, G: 51, A: 255},
+		'o': {R: 255, G: 140, A: 255},
+		'y': {R: 255, G: 224, B: 64, A: 255},
+	})
+)
+
+// A Recipe shows how items are crafted, smelted or upgraded.
+// Items are given by the game's IDs or the names of backgrounds, whose icon is used; empty IDs are empty slots.
+type Recipe struct {
+	Type RecipeType
+
+	// Grid holds the rows of input slots: up to 3x3 for crafting, 2x2 for the inventory, the input of a furnace,
+	// and the template, base and addition in a single row for smithing.
+	Grid [][]string
+
+	// Fuel is burnt by furnaces; it may be empty.
+	Fuel string
+
+	Result string
+	Count  int
+}
+
+// validate checks that the recipe's inputs fit into its GUI.
+func (recipe Recipe) validate() error {
+	size, exists := recipeGrids[recipe.Type]
+	if !exists {
+		return fmt.Errorf("%w, not %q", ErrInvalidRecipeType, recipe.Type)
+	}
+	if len(recipe.Grid) > size.Y {
+		return fmt.Errorf("%w: %d rows instead of up to %d", ErrInvalidRecipeGrid, len(recipe.Grid), size.Y)
+	}
+	for _, row := range recipe.Grid {
+		if len(row) > size.X {
+			return fmt.Errorf("%w: %d columns instead of up to %d", ErrInvalidRecipeGrid, len(row), size.X)
+		}
+	}
+	if recipe.Fuel != "" && recipe.Type != RecipeFurnace {
+		return fmt.Errorf("%w: only furnaces burn fuel", ErrInvalidRecipeGrid)
+	}
+	if recipe.Result == "" {
+		return ErrMissingResult
+	}
+	if recipe.Count < 0 || recipe.Count > MaxCount {
+		return fmt.Errorf("%w, not %d", ErrInvalidCount, recipe.Count)
+	}
+	return nil
+}
+
+// GenerateRecipe renders the GUI panel showing the given recipe and returns it as PNG.
+// It will return an error if the recipe is invalid, an item is unknown, the generator is overloaded or the context is cancelled while waiting.
+func (generator *Generator) GenerateRecipe(ctx context.Context, recipe Recipe) ([]byte, error) {
+	if err := recipe.validate(); err != nil {
+		return nil, err
+	}
+
+	release, err := generator.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	img, err := generator.renderRecipe(recipe)
+	if err != nil {
+		return nil, err
+	}
+	return encodePNG(img)
+}
+
+// renderRecipe draws the inputs, the arrow and the result of a valid recipe onto a panel.
+func (generator *Generator) renderRecipe(recipe Recipe) (image.Image, error) {
+	size := recipeGrids[recipe.Type]
+	inputs := image.Pt(size.X*slotSize, size.Y*slotSize)
+	if recipe.Type == RecipeFurnace {
+		// the flame and fuel are placed below the input
+		inputs.Y = 2*slotSize + flameSize + 4
+	}
+	resultSize := largeSlotSize
+	if recipe.Type == RecipeInventory || recipe.Type == RecipeSmithing {
+		resultSize = slotSize
+	}
+	height := max(inputs.Y, resultSize)
+	panel := image.Rect(0, 0,
+		2*panelPadding+inputs.X+arrowGapBefore+arrowWidth+arrowGapAfter+resultSize,
+		2*panelPadding+height,
+	)
+
+	img := image.NewNRGBA(image.Rectangle{Max: panel.Max.Mul(iconScale)})
+	drawPanel(img, panel)
+
+	// inputs are centred vertically, like the arrow and the result
+	origin := image.Pt(panelPadding, panelPadding+(height-inputs.Y)/2)
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			// slots beyond the given grid stay empty, so the grid shows its full size
+			item := ""
+			if y < len(recipe.Grid) && x < len(recipe.Grid[y]) {
+				item = recipe.Grid[y][x]
+			}
+			if err := generator.drawSlot(img, origin.Add(image.Pt(x*slotSize, y*slotSize)), slotSize, item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if recipe.Type == RecipeFurnace {
+		drawSprite(img, origin.Add(image.Pt((slotSize-flameSize)/2, slotSize+2)), flameSprite)
+		if err := generator.drawSlot(img, origin.Add(image.Pt(0, slotSize+flameSize+4)), slotSize, recipe.Fuel); err != nil {
+			return nil, err
+		}
+	}
+
+	arrow := image.Pt(panelPadding+inputs.X+arrowGapBefore, panelPadding+(height-arrowHeight)/2)
+	drawSprite(img, arrow, arrowSprite)
+
+	result := image.Pt(arrow.X+arrowWidth+arrowGapAfter, panelPadding+(height-resultSize)/2)
+	if err := generator.drawSlot(img, result, resultSize, recipe.Result); err != nil {
+		return nil, err
+	}
+	if recipe.Count <= 1 {
+		return img, nil
+	}
+
+	// we need to lock this because the freetype library (used by gg when running DrawString) is not thread-safe
+	generator.imageWritingLock.Lock()
+	defer generator.imageWritingLock.Unlock()
+
+	_, name, _ := generator.itemIcon(recipe.Result)
+	face, err := generator.fontFace(name)
+	if err != nil {
+		return nil, err
+	}
+	drawCount(img, face, recipe.Count, result.Add(image.Pt((resultSize-itemSize)/2, (resultSize-itemSize)/2)).Mul(iconScale))
+	return img, nil
+}
+
+// itemIcon returns the icon of the given item or background and the name of the background it was taken from.
+// Items are also looked up without the game's namespace, so tags named like backgrounds, e.g. "minecraft:planks", work as well.
+func (generator *Generator) itemIcon(item string) (image.Image, string, error) {
+	background, name, exists := generator.background(item)
+	if !exists {
+		background, name, exists = generator.background(vanillaID(item))
+	}
+	if !exists {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnknownItem, item)
+	}
+
+	icon := image.NewNRGBA(image.Rect(0, 0, assets.IconBounds.Dx(), assets.IconBounds.Dy()))
+	draw.Draw(icon, icon.Bounds(), background, background.Bounds().Min.Add(assets.IconBounds.Min), draw.Src)
+	return icon, name, nil
+}
+
+// drawSlot draws a slot of the given size at the given position in GUI pixels, showing the item's icon unless it is empty.
+func (generator *Generator) drawSlot(img *image.NRGBA, position image.Point, size int, item string) error {
+	slot := image.Rectangle{Min: position, Max: position.Add(image.Pt(size, size))}
+	fillGUI(img, slot, slotFill)
+	fillGUI(img, image.Rect(slot.Min.X, slot.Min.Y, slot.Max.X-1, slot.Min.Y+1), slotShadow)
+	fillGUI(img, image.Rect(slot.Min.X, slot.Min.Y, slot.Min.X+1, slot.Max.Y-1), slotShadow)
+	fillGUI(img, image.Rect(slot.Min.X+1, slot.Max.Y-1, slot.Max.X, slot.Max.Y), guiHighlight)
+	fillGUI(img, image.Rect(slot.Max.X-1, slot.Min.Y+1, slot.Max.X, slot.Max.Y), guiHighlight)
+	if item == "" {
+		return nil
+	}
+
+	icon, _, err := generator.itemIcon(item)
+	if err != nil {
+		return err
+	}
+	offset := (size - itemSize) / 2
+	target := image.Rectangle{Min: position.Add(image.Pt(offset, offset)), Max: position.Add(image.Pt(offset+itemSize, offset+itemSize))}
+	draw.Draw(img, scaleGUI(target), icon, icon.Bounds().Min, draw.Over)
+	return nil
+}
+
+// drawPanel draws the background of a container with the game's rounded outline and bevelled edges.
+func drawPanel(img *image.NRGBA, panel image.Rectangle) {
+	inner := panel.Inset(1)
+	fillGUI(img, inner, guiBackground)
+
+	// outline, leaving out the rounded corners
+	fillGUI(img, image.Rect(panel.Min.X+2, panel.Min.Y, panel.Max.X-3, panel.Min.Y+1), guiOutline)
+	fillGUI(img, image.Rect(panel.Min.X+3, panel.Max.Y-1, panel.Max.X-2, panel.Max.Y), guiOutline)
+	fillGUI(img, image.Rect(panel.Min.X, panel.Min.Y+2, panel.Min.X+1, panel.Max.Y-3), guiOutline)
+	fillGUI(img, image.Rect(panel.Max.X-1, panel.Min.Y+3, panel.Max.X, panel.Max.Y-2), guiOutline)
+	for _, corner := range []image.Point{
+		{panel.Min.X + 1, panel.Min.Y + 1},
+		{panel.Max.X - 3, panel.Min.Y + 1},
+		{panel.Max.X - 2, panel.Min.Y + 2},
+		{panel.Min.X + 1, panel.Max.Y - 3},
+		{panel.Min.X + 2, panel.Max.Y - 2},
+		{panel.Max.X - 2, panel.Max.Y - 2},
+	} {
+		fillGUI(img, image.Rectangle{Min: corner, Max: corner.Add(image.Pt(1, 1))}, guiOutline)
+	}
+	// transparent corners
+	for _, corner := range []image.Rectangle{
+		image.Rect(panel.Min.X, panel.Min.Y, panel.Min.X+1, panel.Min.Y+2),
+		image.Rect(panel.Min.X+1, panel.Min.Y, panel.Min.X+2, panel.Min.Y+1),
+		image.Rect(panel.Max.X-2, panel.Min.Y, panel.Max.X, panel.Min.Y+1),
+		image.Rect(panel.Max.X-1, panel.Min.Y+1, panel.Max.X, panel.Min.Y+3),
+		image.Rect(panel.Min.X, panel.Max.Y-3, panel.Min.X+1, panel.Max.Y),
+		image.Rect(panel.Min.X+1, panel.Max.Y-2, panel.Min.X+2, panel.Max.Y),
+		image.Rect(panel.Max.X-2, panel.Max.Y-1, panel.Max.X, panel.Max.Y),
+		image.Rect(panel.Max.X-1, panel.Max.Y-2, panel.Max.X, panel.Max.Y),
+	} {
+		draw.Draw(img, scaleGUI(corner), image.Transparent, image.Point{}, draw.Src)
+	}
+
+	// bevelled edges: highlighted at the top left and shadowed at the bottom right
+	fillGUI(img, image.Rect(inner.Min.X+1, inner.Min.Y, inner.Max.X-2, inner.Min.Y+2), guiHighlight)
+	fillGUI(img, image.Rect(inner.Min.X, inner.Min.Y+1, inner.Min.X+2, inner.Max.Y-2), guiHighlight)
+	fillGUI(img, image.Rect(inner.Min.X+2, inner.Max.Y-2, inner.Max.X-1, inner.Max.Y), guiShadow)
+	fillGUI(img, image.Rect(inner.Max.X-2, inner.Min.Y+2, inner.Max.X, inner.Max.Y-1), guiShadow)
+}
+
+// fillGUI fills the given rectangle in GUI pixels with a colour.
+func fillGUI(img *image.NRGBA, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, scaleGUI(rect), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// scaleGUI converts the given rectangle from GUI pixels to pixels of the image.
+func scaleGUI(rect image.Rectangle) image.Rectangle {
+	return image.Rectangle{Min: rect.Min.Mul(iconScale), Max: rect.Max.Mul(iconScale)}
+}
+
+// sprite converts the given rows of pixels, one character each, into an image; unknown characters are transparent.
+func sprite(rows []string, palette map[byte]color.RGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x := 0; x < len(row); x++ {
+			if c, exists := palette[row[x]]; exists {
+				img.Set(x, y, c)
+			}
+		}
+	}
+	return img
+}
+
+// drawSprite draws the sprite at the given position in GUI pixels.
+func drawSprite(img *image.NRGBA, position image.Point, sprite *image.NRGBA) {
+	for y := 0; y < sprite.Bounds().Dy(); y++ {
+		for x := 0; x < sprite.Bounds().Dx(); x++ {
+			if c := sprite.NRGBAAt(x, y); c.A > 0 {
+				fillGUI(img, image.Rect(position.X+x, position.Y+y, position.X+x+1, position.Y+y+1), c)
+			}
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package generator
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/menzerath/mcgen/assets"
+	xdraw "golang.org/x/image/draw"
+)
+
+// AssetKind identifies the kind of an asset in a Registry.
+type AssetKind string
+
+// list of asset kinds managed by a Registry
+const (
+	KindBackground AssetKind = "background"
+	KindFrame      AssetKind = "frame"
+	KindIcon       AssetKind = "icon"
+	KindFont       AssetKind = "font"
+	KindBlock      AssetKind = "block"
+)
+
+// AssetKinds lists all asset kinds.
+var AssetKinds = []AssetKind{KindBackground, KindFrame, KindIcon, KindFont, KindBlock}
+
+// A Change describes an asset that was added, replaced or removed.
+type Change struct {
+	Kind    AssetKind
+	Name    string
+	Removed bool
+}
+
+// A Registry holds all assets used to generate images and is safe for concurrent use.
+//
+// Backgrounds, icons and blocks share their names: looking up a background returns the icon or rendered block of that name drawn on a frame, if there is no background.
+// Icons are drawn on the default frame of their namespace ("mypack:default"), falling back to the global default frame.
+// Blocks are rendered from the icons or backgrounds named as their textures whenever they are looked up, so they follow changes of their textures.
+// Fonts are chosen the same way, so the font "mypack:default" is used for all backgrounds in the namespace "mypack".
+type Registry struct {
+	lock        sync.RWMutex
+	backgrounds map[string]image.Image
+	frames      map[string]image.Image
+	icons       map[string]image.Image
+	fonts       map[string]*truetype.Font
+	blocks      map[string]assets.Block
+
+	subscribers      map[int]func(Change)
+	nextSubscriberID int
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		backgrounds: make(map[string]image.Image),
+		frames:      make(map[string]image.Image),
+		icons:       make(map[string]image.Image),
+		fonts:       make(map[string]*truetype.Font),
+		blocks:      make(map[string]assets.Block),
+		subscribers: make(map[int]func(Change)),
+	}
+}
+
+// Background returns the background with the given name, which may be an icon drawn on its frame.
+func (registry *Registry) Background(name string) (image.Image, bool) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	if background, exists := registry.backgrounds[name]; exists {
+		return background, true
+	}
+	icon, exists := registry.icons[name]
+	if !exists {
+		icon, exists = registry.renderBlock(name)
+	}
+	if !exists {
+		return nil, false
+	}
+	frame, exists := registry.defaultFrame(name)
+	if !exists {
+		return nil, false
+	}
+	return drawIcon(frame, icon), true
+}
+
+// Frame returns the frame with the given name.
+func (registry *Registry) Frame(name string) (image.Image, bool) {
+	return lookup(registry, registry.frames, name)
+}
+
+// FrameFor returns the frame the background with the given name is based on, i.e. the default frame of its namespace.
+func (registry *Registry) FrameFor(background string) (image.Image, bool) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	return registry.defaultFrame(background)
+}
+
+// Icon returns the icon with the given name.
+func (registry *Registry) Icon(name string) (image.Image, bool) {
+	return lookup(registry, registry.icons, name)
+}
+
+// Block returns the block with the given name.
+func (registry *Registry) Block(name string) (assets.Block, bool) {
+	return lookup(registry, registry.blocks, name)
+}
+
+// Texture returns the icon with the given name, or the icon of the background with the given name.
+func (registry *Registry) Texture(name string) (image.Image, bool) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	return registry.texture(name)
+}
+
+// Font returns the font with the given name.
+func (registry *Registry) Font(name string) (*truetype.Font, bool) {
+	return lookup(registry, registry.fonts, name)
+}
+
+// FontFor returns the name of the font used for the background with the given name.
+func (registry *Registry) FontFor(background string) (string, bool) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	if namespace, _, found := strings.Cut(background, ":"); found {
+		name := namespace + ":" + assets.DefaultFont
+		if _, exists := registry.fonts[name]; exists {
+			return name, true
+		}
+	}
+	_, exists := registry.fonts[assets.DefaultFont]
+	return assets.DefaultFont, exists
+}
+
+// Names returns the sorted names of all assets of the given kind.
+// Icons are not listed as backgrounds.
+func (registry *Registry) Names(kind AssetKind) []string {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	switch kind {
+	case KindBackground:
+		return slices.Sorted(maps.Keys(registry.backgrounds))
+	case KindFrame:
+		return slices.Sorted(maps.Keys(registry.frames))
+	case KindIcon:
+		return slices.Sorted(maps.Keys(registry.icons))
+	case KindFont:
+		return slices.Sorted(maps.Keys(registry.fonts))
+	case KindBlock:
+		return slices.Sorted(maps.Keys(registry.blocks))
+	}
+	return nil
+}
+
+// AddBackground adds a background of 320x64 pixels.
+// It fails with ErrAssetExists if a background or icon of that name exists, unless replace is set.
+func (registry *Registry) AddBackground(name string, background image.Image, replace bool) error {
+	if err := assets.CheckBackground(background); err != nil {
+		return err
+	}
+	return add(registry, KindBackground, registry.backgrounds, name, background, replace)
+}
+
+// AddFrame adds an empty background of 320x64 pixels icons are drawn on.
+// It fails with ErrAssetExists if a frame of that name exists, unless replace is set.
+func (registry *Registry) AddFrame(name string, frame image.Image, replace bool) error {
+	if err := assets.CheckBackground(frame); err != nil {
+		return err
+	}
+	return add(registry, KindFrame, registry.frames, name, frame, replace)
+}
+
+// AddIcon adds an icon of 16x16 or 32x32 pixels.
+// It fails with ErrAssetExists if a background or icon of that name exists, unless replace is set.
+func (registry *Registry) AddIcon(name string, icon image.Image, replace bool) error {
+	if err := assets.CheckIcon(icon); err != nil {
+		return err
+	}
+	return add(registry, KindIcon, registry.icons, name, icon, replace)
+}
+
+// AddFont adds a font.
+// It fails with ErrAssetExists if a font of that name exists, unless replace is set.
+func (registry *Registry) AddFont(name string, parsedFont *truetype.Font, replace bool) error {
+	return add(registry, KindFont, registry.fonts, name, parsedFont, replace)
+}
+
+// AddBlock adds a block rendered from the textures it declares.
+// It fails with ErrAssetExists if a background, icon or block of that name exists, unless replace is set.
+func (registry *Registry) AddBlock(name string, block assets.Block, replace bool) error {
+	if err := block.Validate(); err != nil {
+		return err
+	}
+	return add(registry, KindBlock, registry.blocks, name, block.WithDefaults(), replace)
+}
+
+// Remove removes the asset of the given kind and name and reports whether it existed.
+// It fails with ErrDefaultAsset for the default frame and font, which all renders depend on.
+func (registry *Registry) Remove(kind AssetKind, name string) (bool, error) {
+	if (kind == KindFrame && name == assets.DefaultFrame) || (kind == KindFont && name == assets.DefaultFont) {
+		return false, fmt.Errorf("%w: %s %s", ErrDefaultAsset, kind, name)
+	}
+
+	registry.lock.Lock()
+	var removed bool
+	switch kind {
+	case KindBackground:
+		removed = remove(registry.backgrounds, name)
+	case KindFrame:
+		removed = remove(registry.frames, name)
+	case KindIcon:
+		removed = remove(registry.icons, name)
+	case KindFont:
+		removed = remove(registry.fonts, name)
+	case KindBlock:
+		removed = remove(registry.blocks, name)
+	}
+	registry.lock.Unlock()
+
+	if removed {
+		registry.notify(Change{Kind: kind, Name: name, Removed: true})
+	}
+	return removed, nil
+}
+
+// Subscribe calls the given function after every change of the registry's assets until the returned function is called.
+// It is called synchronously by the goroutine changing the registry, so it must not block.
+func (registry *Registry) Subscribe(subscriber func(Change)) (unsubscribe func()) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	id := registry.nextSubscriberID
+	registry.nextSubscriberID++
+	registry.subscribers[id] = subscriber
+
+	return func() {
+		registry.lock.Lock()
+		defer registry.lock.Unlock()
+		delete(registry.subscribers, id)
+	}
+}
+
+// AddPack adds all assets of the given pack within its namespace.
+// The pack's font is added as the default font of its namespace.
+func (registry *Registry) AddPack(pack assets.Pack, replace bool) error {
+	for name, frame := range pack.Frames {
+		if err := registry.AddFrame(pack.Name(name), frame, replace); err != nil {
+			return err
+		}
+	}
+	for name, background := range pack.Backgrounds {
+		if err := registry.AddBackground(pack.Name(name), background, replace); err != nil {
+			return err
+		}
+	}
+	for name, icon := range pack.Icons {
+		if _, exists := pack.Backgrounds[name]; exists {
+			return fmt.Errorf("%w: icon %s is also a background", ErrAssetExists, name)
+		}
+		if err := registry.AddIcon(pack.Name(name), icon, replace); err != nil {
+			return err
+		}
+	}
+	for name, block := range pack.Blocks {
+		if _, exists := pack.Backgrounds[name]; exists {
+			return fmt.Errorf("%w: block %s is also a background", ErrAssetExists, name)
+		}
+		if _, exists := pack.Icons[name]; exists {
+			return fmt.Errorf("%w: block %s is also an icon", ErrAssetExists, name)
+		}
+		if err := registry.AddBlock(pack.Name(name), block, replace); err != nil {
+			return err
+		}
+	}
+	if pack.Font != nil {
+		if err := registry.AddFont(pack.Name(assets.DefaultFont), pack.Font, replace); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("loaded pack", "path", pack.Path, "namespace", pack.Namespace, "backgrounds", len(pack.Backgrounds), "icons", len(pack.Icons), "blocks", len(pack.Blocks), "frames", len(pack.Frames))
+	return nil
+}
+
+// add adds an asset to the given map of the registry and notifies all subscribers.
+// Backgrounds, icons and blocks conflict with each other, as they share their names; replacing one removes the others.
+func add[T any](registry *Registry, kind AssetKind, target map[string]T, name string, asset T, replace bool) error {
+	if err := assets.ValidateName(name); err != nil {
+		return err
+	}
+
+	registry.lock.Lock()
+	_, exists := target[name]
+	existsOther := registry.sharesName(kind, name)
+	if (exists || existsOther) && !replace {
+		registry.lock.Unlock()
+		return fmt.Errorf("%w: %s %s", ErrAssetExists, kind, name)
+	}
+	if existsOther {
+		registry.releaseName(kind, name)
+	}
+	target[name] = asset
+	registry.lock.Unlock()
+
+	if exists || existsOther {
+		slog.Info("replaced asset", "kind", kind, "name", name)
+	}
+	registry.notify(Change{Kind: kind, Name: name})
+	return nil
+}
+
+// sharesName reports whether another kind of asset looked up as background has the given name.
+// The registry's lock must be held.
+func (registry *Registry) sharesName(kind AssetKind, name string) bool {
+	_, background := registry.backgrounds[name]
+	_, icon := registry.icons[name]
+	_, block := registry.blocks[name]
+	switch kind {
+	case KindBackground:
+		return icon || block
+	case KindIcon:
+		return background || block
+	case KindBlock:
+		return background || icon
+	}
+	return false
+}
+
+// releaseName removes the assets looked up as background with the given name, except for those of the given kind.
+// The registry's lock must be held.
+func (registry *Registry) releaseName(kind AssetKind, name string) {
+	if kind != KindBackground {
+		delete(registry.backgrounds, name)
+	}
+	if kind != KindIcon {
+		delete(registry.icons, name)
+	}
+	if kind != KindBlock {
+		delete(registry.blocks, name)
+	}
+}
+
+// texture returns the icon with the given name, or the icon of the background with the given name.
+// The registry's lock must be held.
+func (registry *Registry) texture(name string) (image.Image, bool) {
+	if icon, exists := registry.icons[name]; exists {
+		return icon, true
+	}
+	if background, exists := registry.backgrounds[name]; exists {
+		icon := image.NewNRGBA(image.Rect(0, 0, assets.IconBounds.Dx(), assets.IconBounds.Dy()))
+		draw.Draw(icon, icon.Bounds(), background, background.Bounds().Min.Add(assets.IconBounds.Min), draw.Src)
+		return icon, true
+	}
+	return nil, false
+}
+
+// renderBlock renders the block with the given name as icon.
+// Missing textures are left out.
+// The registry's lock must be held.
+func (registry *Registry) renderBlock(name string) (image.Image, bool) {
+	block, exists := registry.blocks[name]
+	if !exists {
+		return nil, false
+	}
+
+	var faces BlockFaces
+	faces.Top, _ = registry.texture(block.Top)
+	faces.Side, _ = registry.texture(block.Side)
+	faces.Front, _ = registry.texture(block.Front)
+	return RenderBlock(faces, assets.IconBounds.Dx()), true
+}
+
+// lookup returns the asset of the given name from the given map of the registry.
+func lookup[T any](registry *Registry, source map[string]T, name string) (T, bool) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	asset, exists := source[name]
+	return asset, exists
+}
+
+// defaultFrame returns the default frame of the given asset's namespace, or the global default frame if there is none.
+// The registry's lock must be held.
+func (registry *Registry) defaultFrame(name string) (image.Image, bool) {
+	if namespace, _, found := strings.Cut(name, ":"); found {
+		if frame, exists := registry.frames[namespace+":"+assets.DefaultFrame]; exists {
+			return frame, true
+		}
+	}
+	frame, exists := registry.frames[assets.DefaultFrame]
+	return frame, exists
+}
+
+// notify passes the given change to all subscribers.
+func (registry *Registry) notify(change Change) {
+	registry.lock.RLock()
+	subscribers := make([]func(Change), 0, len(registry.subscribers))
+	for _, subscriber := range registry.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	registry.lock.RUnlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(change)
+	}
+}
+
+func remove[T any](source map[string]T, name string) bool {
+	_, exists := source[name]
+	delete(source, name)
+	return exists
+}
+
+// drawIcon returns a background showing the given icon on the given frame.
+// Icons smaller than the frame's icon area are scaled up without smoothing, like the game does.
+func drawIcon(frame image.Image, icon image.Image) image.Image {
+	background := image.NewNRGBA(frame.Bounds())
+	draw.Draw(background, background.Bounds(), frame, frame.Bounds().Min, draw.Src)
+	xdraw.NearestNeighbor.Scale(background, assets.IconBounds, icon, icon.Bounds(), draw.Over, nil)
+	return background
+}
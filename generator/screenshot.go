@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// limits for screenshots
+const (
+	MaxScreenshotDimension = 8192
+	MaxGUIScale            = 16
+)
+
+// list of errors returned when placing achievements onto screenshots
+var (
+	ErrInvalidScreenshot = fmt.Errorf("invalid screenshot")
+	ErrInvalidGUIScale   = fmt.Errorf("invalid gui scale")
+)
+
+// the game's toasts are 160x32 gui pixels large, while our backgrounds are rendered at twice that size
+const backgroundScale = 2
+
+// the minimum size of the game's window in gui pixels, used to determine the automatic gui scale
+const (
+	minimumGUIWidth  = 320
+	minimumGUIHeight = 240
+)
+
+// GUIScale returns the gui scale the game would automatically choose for a window of the given size.
+func GUIScale(width int, height int) int {
+	scale := 1
+	for scale < width && scale < height && width/(scale+1) >= minimumGUIWidth && height/(scale+1) >= minimumGUIHeight {
+		scale++
+	}
+	return scale
+}
+
+// GenerateOnScreenshot places an achievement onto the given screenshot at the position the game would show it.
+// A guiScale of zero detects the scale from the screenshot's resolution.
+// It returns the screenshot in its original format and resolution together with the name of that format.
+func (generator *Generator) GenerateOnScreenshot(ctx context.Context, screenshot []byte, achievement Achievement, guiScale int) ([]byte, string, error) {
+	if guiScale < 0 || guiScale > MaxGUIScale {
+		return nil, "", ErrInvalidGUIScale
+	}
+
+	release, err := generator.limiter.acquire(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer release()
+
+	// check the dimensions before decoding the whole image to avoid allocating huge images
+	config, _, err := image.DecodeConfig(bytes.NewReader(screenshot))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidScreenshot, err)
+	}
+	if config.Width > MaxScreenshotDimension || config.Height > MaxScreenshotDimension {
+		return nil, "", fmt.Errorf("%w: larger than %dx%d pixels", ErrInvalidScreenshot, MaxScreenshotDimension, MaxScreenshotDimension)
+	}
+
+	decoded, format, err := image.Decode(bytes.NewReader(screenshot))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidScreenshot, err)
+	}
+	bounds := decoded.Bounds()
+	if guiScale == 0 {
+		guiScale = GUIScale(bounds.Dx(), bounds.Dy())
+	}
+
+	toast, err := generator.render(achievement)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// scale the toast to the gui scale and place it in the top-right corner
+	toastSize := toast.Bounds().Size().Mul(guiScale).Div(backgroundScale)
+	if toastSize.X > bounds.Dx() || toastSize.Y > bounds.Dy() {
+		return nil, "", fmt.Errorf("%w: screenshot too small for gui scale %d", ErrInvalidGUIScale, guiScale)
+	}
+	result := image.NewRGBA(bounds)
+	draw.Draw(result, bounds, decoded, bounds.Min, draw.Src)
+	target := image.Rectangle{
+		Min: image.Pt(bounds.Max.X-toastSize.X, bounds.Min.Y),
+		Max: image.Pt(bounds.Max.X, bounds.Min.Y+toastSize.Y),
+	}
+	xdraw.NearestNeighbor.Scale(result, target, toast, toast.Bounds(), xdraw.Over, nil)
+
+	// encode the result in the screenshot's original format
+	buffer := new(bytes.Buffer)
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(buffer, result, &jpeg.Options{Quality: 95})
+	case "gif":
+		err = gif.Encode(buffer, result, nil)
+	default:
+		format = "png"
+		err = png.Encode(buffer, result)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("encoding image: %w", err)
+	}
+
+	return buffer.Bytes(), format, nil
+}
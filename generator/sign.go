@@ -0,0 +1,221 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/menzerath/mcgen/assets"
+)
+
+// MaxSignLines is the number of lines on either kind of sign.
+const MaxSignLines = 4
+
+// list of errors returned when rendering signs
+var (
+	ErrUnknownWoodType  = fmt.Errorf("unknown wood type")
+	ErrTooManySignLines = fmt.Errorf("signs must not have more than %d lines", MaxSignLines)
+	ErrSignLineTooWide  = fmt.Errorf("line is wider than the sign")
+)
+
+// defaultSignColor is the dye of signs that have not been dyed.
+const defaultSignColor = "black"
+
+// factors applied to the colours of the sign's wood and text
+const (
+	signTextDarkening   = 0.4
+	signGrainStrength   = 0.08
+	signBorderDarkening = 0.8
+	signPostDarkening   = 0.85
+)
+
+var (
+	// glowingBlackOutline is the outline of glowing black text, which the game lightens instead of darkening it.
+	glowingBlackOutline = color.RGBA{R: 0xf0, G: 0xeb, B: 0xcc, A: 0xff}
+
+	// colours of the alternating links of a hanging sign's chains
+	chainLight = color.RGBA{R: 96, G: 100, B: 112, A: 255}
+	chainDark  = color.RGBA{R: 45, G: 48, B: 56, A: 255}
+)
+
+// signLayout is the shape of a kind of sign; sizes are given in pixels of the game's textures, which are shown as
+// texelSize pixels of the image, and in pixels of the font for the text.
+type signLayout struct {
+	board      image.Rectangle
+	size       image.Point
+	maxWidth   int
+	lineHeight int
+}
+
+// texelSize is the size of a pixel of the sign's texture, four pixels of the font like in the game.
+const texelSize = 4 * iconScale
+
+var (
+	// standingSign is a board on a post.
+	standingSign = signLayout{
+		board:      image.Rect(0, 0, 24, 12),
+		size:       image.Pt(24, 26),
+		maxWidth:   90,
+		lineHeight: lineHeight,
+	}
+	// hangingSign is a smaller board hanging from two chains.
+	hangingSign = signLayout{
+		board:      image.Rect(0, 6, 16, 16),
+		size:       image.Pt(16, 16),
+		maxWidth:   60,
+		lineHeight: 9,
+	}
+)
+
+// A Sign shows up to four lines of centred text on the front of a sign or hanging sign.
+type Sign struct {
+	// Wood is the ID of the wood type, e.g. "oak" or "minecraft:cherry".
+	Wood    string
+	Hanging bool
+
+	// Lines may contain the game's formatting codes; like in the game, each line must fit onto the sign.
+	Lines []string
+
+	// Color is the ID of the dye applied to the sign, which defaults to black.
+	// Glowing text is lighter and outlined, like after applying a glow ink sac.
+	Color   string
+	Glowing bool
+}
+
+// GenerateSign renders the front of the given sign and returns it as PNG.
+// It will return an error if the sign is invalid, a line is too wide, the generator is overloaded or the context is cancelled while waiting.
+func (generator *Generator) GenerateSign(ctx context.Context, sign Sign) ([]byte, error) {
+	wood, exists := assets.WoodTypes[vanillaID(sign.Wood)]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownWoodType, sign.Wood)
+	}
+	if len(sign.Lines) > MaxSignLines {
+		return nil, fmt.Errorf("%w, not %d", ErrTooManySignLines, len(sign.Lines))
+	}
+	if sign.Color == "" {
+		sign.Color = defaultSignColor
+	}
+	dye, exists := assets.DyeTextColors[vanillaID(sign.Color)]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDyeColor, sign.Color)
+	}
+
+	release, err := generator.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	img, err := generator.renderSign(sign, wood, dye)
+	if err != nil {
+		return nil, err
+	}
+	return encodePNG(img)
+}
+
+// renderSign draws the sign in the wood's colours and writes its lines onto the board.
+func (generator *Generator) renderSign(sign Sign, wood assets.WoodType, dye color.RGBA) (image.Image, error) {
+	layout := standingSign
+	if sign.Hanging {
+		layout = hangingSign
+	}
+	img := image.NewNRGBA(image.Rectangle{Max: layout.size.Mul(texelSize)})
+
+	if sign.Hanging {
+		// chains at both sides of the board, made of alternating links
+		for _, x := range []int{2, layout.board.Max.X - 3} {
+			for y := 0; y < layout.board.Min.Y; y++ {
+				c := chainLight
+				if y%2 == 1 {
+					c = chainDark
+				}
+				fillTexel(img, image.Pt(x, y), c)
+			}
+		}
+		drawBoard(img, layout.board, wood.Log)
+	} else {
+		post := image.Rect(layout.board.Dx()/2-1, layout.board.Max.Y, layout.board.Dx()/2+1, layout.size.Y)
+		drawBoard(img, post, scaleColor(wood.Log, signPostDarkening))
+		drawBoard(img, layout.board, wood.Planks)
+	}
+
+	// like the game, text is darkened unless it glows, and glowing text is outlined in the darkened colour
+	dark := scaleColor(dye, signTextDarkening)
+	if sign.Glowing && vanillaID(sign.Color) == defaultSignColor {
+		dark = glowingBlackOutline
+	}
+	textColor := color.Color(dark)
+	if sign.Glowing {
+		textColor = dye
+	}
+
+	// we need to lock this because the freetype library is not thread-safe
+	generator.imageWritingLock.Lock()
+	defer generator.imageWritingLock.Unlock()
+
+	face, err := generator.fontFace(assets.DefaultFont)
+	if err != nil {
+		return nil, err
+	}
+
+	center := layout.board.Min.Add(layout.board.Max).Mul(texelSize / 2)
+	for i, line := range sign.Lines {
+		spans := ParseFormatting(line, TextStyle{Color: textColor})
+		width := lineWidth(face, spans)
+		if width > layout.maxWidth*iconScale {
+			return nil, fmt.Errorf("%w: line %d is %d pixels wide instead of up to %d", ErrSignLineTooWide, i+1, width/iconScale, layout.maxWidth)
+		}
+
+		top := center.Y + (i-MaxSignLines/2)*layout.lineHeight*iconScale
+		origin := image.Pt(center.X-width/2, top+glyphHeight*iconScale)
+		if sign.Glowing {
+			outline := make([]TextSpan, len(spans))
+			for j, span := range spans {
+				outline[j] = span
+				outline[j].Style.Color = dark
+			}
+			for _, offset := range []image.Point{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}} {
+				drawLine(img, face, outline, origin.Add(offset.Mul(iconScale)), false)
+			}
+		}
+		drawLine(img, face, spans, origin, false)
+	}
+	return img, nil
+}
+
+// drawBoard fills the given rectangle of texels with the wood's colour, adding grain along its rows and a darker edge.
+func drawBoard(img *image.NRGBA, board image.Rectangle, wood color.RGBA) {
+	for y := board.Min.Y; y < board.Max.Y; y++ {
+		for x := board.Min.X; x < board.Max.X; x++ {
+			// the grain changes slowly along the rows, so it looks like the fibres of the wood
+			factor := 1 + signGrainStrength*(grain(x/3, y)+grain(x/3+1, y))/2
+			if x == board.Min.X || y == board.Min.Y || x == board.Max.X-1 || y == board.Max.Y-1 {
+				factor *= signBorderDarkening
+			}
+			fillTexel(img, image.Pt(x, y), scaleColor(wood, factor))
+		}
+	}
+}
+
+// grain returns a pseudo-random value between -1 and 1 for the given texel, which is the same for all images.
+func grain(x int, y int) float64 {
+	hash := uint32(x)*374761393 + uint32(y)*668265263
+	hash = (hash ^ (hash >> 13)) * 1274126177
+	return float64(hash&0xffff)/0x7fff - 1
+}
+
+// fillTexel fills the given pixel of the texture.
+func fillTexel(img *image.NRGBA, texel image.Point, c color.Color) {
+	rect := image.Rectangle{Min: texel.Mul(texelSize), Max: texel.Add(image.Pt(1, 1)).Mul(texelSize)}
+	draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// scaleColor multiplies the brightness of the given colour by the factor.
+func scaleColor(c color.RGBA, factor float64) color.RGBA {
+	scale := func(value uint8) uint8 {
+		return uint8(min(255, float64(value)*factor))
+	}
+	return color.RGBA{R: scale(c.R), G: scale(c.G), B: scale(c.B), A: c.A}
+}
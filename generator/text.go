@@ -0,0 +1,309 @@
+package generator
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// formattingCode introduces a formatting code of the game, e.g. "§c" for red text.
+const formattingCode = '§'
+
+// layout of text, in pixels of the game's GUI which are doubled like the toast's icon
+const (
+	lineHeight         = 10
+	glyphHeight        = 7
+	strikethroughY     = 4
+	italicSkew         = 0.25
+	obfuscatedGlyphSet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// TextColors are the named colours of the game's text, e.g. for formatting codes and text components.
+var TextColors = map[string]color.RGBA{
+	"black":        {A: 255},
+	"dark_blue":    {B: 170, A: 255},
+	"dark_green":   {G: 170, A: 255},
+	"dark_aqua":    {G: 170, B: 170, A: 255},
+	"dark_red":     {R: 170, A: 255},
+	"dark_purple":  {R: 170, B: 170, A: 255},
+	"gold":         {R: 255, G: 170, A: 255},
+	"gray":         {R: 170, G: 170, B: 170, A: 255},
+	"dark_gray":    {R: 85, G: 85, B: 85, A: 255},
+	"blue":         {R: 85, G: 85, B: 255, A: 255},
+	"green":        {R: 85, G: 255, B: 85, A: 255},
+	"aqua":         {R: 85, G: 255, B: 255, A: 255},
+	"red":          {R: 255, G: 85, B: 85, A: 255},
+	"light_purple": {R: 255, G: 85, B: 255, A: 255},
+	"yellow":       {R: 255, G: 255, B: 85, A: 255},
+	"white":        {R: 255, G: 255, B: 255, A: 255},
+}
+
+// colorCodes maps the formatting codes of colours to their names.
+var colorCodes = map[rune]string{
+	'0': "black", '1': "dark_blue", '2': "dark_green", '3': "dark_aqua",
+	'4': "dark_red", '5': "dark_purple", '6': "gold", '7': "gray",
+	'8': "dark_gray", '9': "blue", 'a': "green", 'b': "aqua",
+	'c': "red", 'd': "light_purple", 'e': "yellow", 'f': "white",
+}
+
+// TextStyle is the colour and formatting of text.
+type TextStyle struct {
+	Color         color.Color
+	Bold          bool
+	Italic        bool
+	Underlined    bool
+	Strikethrough bool
+	Obfuscated    bool
+}
+
+// A TextSpan is text drawn in a single style.
+type TextSpan struct {
+	Text  string
+	Style TextStyle
+}
+
+// ParseFormatting splits the given text at the game's formatting codes, e.g. "§c§lDanger", into spans starting in the base style.
+// Like in the game, colours reset all formatting, "§r" resets to the base style and unknown codes are dropped.
+func ParseFormatting(text string, base TextStyle) []TextSpan {
+	var spans []TextSpan
+	style := base
+	var builder strings.Builder
+	flush := func() {
+		if builder.Len() > 0 {
+			spans = append(spans, TextSpan{Text: builder.String(), Style: style})
+			builder.Reset()
+		}
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != formattingCode {
+			builder.WriteRune(runes[i])
+			continue
+		}
+		if i+1 == len(runes) {
+			break
+		}
+		i++
+		flush()
+
+		code := []rune(strings.ToLower(string(runes[i])))[0]
+		if name, exists := colorCodes[code]; exists {
+			style = TextStyle{Color: TextColors[name]}
+			continue
+		}
+		switch code {
+		case 'k':
+			style.Obfuscated = true
+		case 'l':
+			style.Bold = true
+		case 'm':
+			style.Strikethrough = true
+		case 'n':
+			style.Underlined = true
+		case 'o':
+			style.Italic = true
+		case 'r':
+			style = base
+		}
+	}
+	flush()
+	return spans
+}
+
+// StripFormatting returns the given text as it is shown, without the game's formatting codes.
+func StripFormatting(text string) string {
+	return plainText(ParseFormatting(text, TextStyle{}))
+}
+
+// plainText returns the text of the given spans without formatting.
+func plainText(spans []TextSpan) string {
+	var builder strings.Builder
+	for _, span := range spans {
+		builder.WriteString(span.Text)
+	}
+	return builder.String()
+}
+
+// lineWidth returns the width of the given spans in pixels; bold glyphs are one GUI pixel wider.
+// As freetype is not thread-safe, the caller must hold the generator's imageWritingLock.
+func lineWidth(face font.Face, spans []TextSpan) int {
+	width := 0
+	for _, span := range spans {
+		width += spanWidth(face, span)
+	}
+	return width
+}
+
+func spanWidth(face font.Face, span TextSpan) int {
+	width := font.MeasureString(face, span.Text).Round()
+	if span.Style.Bold {
+		width += utf8.RuneCountInString(span.Text) * iconScale
+	}
+	return width
+}
+
+// drawLine draws the given spans starting at the given point on the baseline and returns their width.
+// Like the game, the shadow is a quarter of the text's colour and offset by one GUI pixel.
+// As freetype is not thread-safe, the caller must hold the generator's imageWritingLock.
+func drawLine(dst draw.Image, face font.Face, spans []TextSpan, origin image.Point, shadow bool) int {
+	if shadow {
+		x := origin.X + iconScale
+		for _, span := range spans {
+			x += drawSpan(dst, face, span, image.Pt(x, origin.Y+iconScale), shadowColor(span.Style.Color))
+		}
+	}
+	x := origin.X
+	for _, span := range spans {
+		x += drawSpan(dst, face, span, image.Pt(x, origin.Y), span.Style.Color)
+	}
+	return x - origin.X
+}
+
+// drawSpan draws the span in the given colour starting at the given point on the baseline and returns its width.
+func drawSpan(dst draw.Image, face font.Face, span TextSpan, origin image.Point, c color.Color) int {
+	text := span.Text
+	if span.Style.Obfuscated {
+		text = obfuscate(face, text)
+	}
+
+	// draw the glyphs onto a mask first, so italic text can be skewed
+	metrics := face.Metrics()
+	ascent, descent := metrics.Ascent.Ceil(), metrics.Descent.Ceil()
+	padding := 2 * iconScale
+	width := spanWidth(face, TextSpan{Text: text, Style: span.Style})
+	mask := image.NewAlpha(image.Rect(0, 0, width+2*padding, ascent+descent))
+	drawer := font.Drawer{Dst: mask, Src: image.Opaque, Face: face, Dot: fixed.P(padding, ascent)}
+	for _, r := range text {
+		dot := drawer.Dot
+		drawer.DrawString(string(r))
+		if span.Style.Bold {
+			// the game draws bold glyphs twice, the second time one pixel to the right
+			advance := drawer.Dot
+			drawer.Dot = dot.Add(fixed.P(iconScale, 0))
+			drawer.DrawString(string(r))
+			drawer.Dot = advance.Add(fixed.P(iconScale, 0))
+		}
+	}
+	if span.Style.Italic {
+		mask = skew(mask, ascent)
+	}
+
+	src := image.NewUniform(c)
+	topLeft := origin.Sub(image.Pt(padding, ascent))
+	draw.DrawMask(dst, mask.Bounds().Add(topLeft), src, image.Point{}, mask, image.Point{}, draw.Over)
+	if span.Style.Underlined {
+		line := image.Rect(origin.X-iconScale, origin.Y+iconScale, origin.X+width, origin.Y+2*iconScale)
+		draw.Draw(dst, line, src, image.Point{}, draw.Over)
+	}
+	if span.Style.Strikethrough {
+		line := image.Rect(origin.X-iconScale, origin.Y-strikethroughY*iconScale, origin.X+width, origin.Y-(strikethroughY-1)*iconScale)
+		draw.Draw(dst, line, src, image.Point{}, draw.Over)
+	}
+	return width
+}
+
+// skew shifts the rows of the mask like the game slants italic glyphs: the top by one GUI pixel to the right, the bottom to the left.
+func skew(mask *image.Alpha, baseline int) *image.Alpha {
+	skewed := image.NewAlpha(mask.Bounds())
+	top := baseline - glyphHeight*iconScale
+	for y := 0; y < mask.Bounds().Dy(); y++ {
+		row := float64(y-top) / iconScale
+		shift := int(math.Round((1 - italicSkew*row) * iconScale))
+		for x := 0; x < mask.Bounds().Dx(); x++ {
+			if target := x + shift; target >= 0 && target < mask.Bounds().Dx() {
+				skewed.SetAlpha(target, y, mask.AlphaAt(x, y))
+			}
+		}
+	}
+	return skewed
+}
+
+// obfuscate replaces all glyphs by others of the same width, like the game's ever-changing obfuscated text.
+// The replacements depend on the text only, so images stay the same.
+func obfuscate(face font.Face, text string) string {
+	var builder strings.Builder
+	for i, r := range text {
+		advance, _ := face.GlyphAdvance(r)
+		replacement := r
+		for j := range len(obfuscatedGlyphSet) {
+			candidate := rune(obfuscatedGlyphSet[(int(r)*31+i*7+j)%len(obfuscatedGlyphSet)])
+			if candidateAdvance, _ := face.GlyphAdvance(candidate); candidateAdvance == advance {
+				replacement = candidate
+				break
+			}
+		}
+		if r == ' ' {
+			replacement = r
+		}
+		builder.WriteRune(replacement)
+	}
+	return builder.String()
+}
+
+// shadowColor returns the colour of the given text colour's shadow, a quarter of its brightness.
+func shadowColor(c color.Color) color.Color {
+	r, g, b, a := c.RGBA()
+	return color.RGBA64{R: uint16(r / 4), G: uint16(g / 4), B: uint16(b / 4), A: uint16(a)}
+}
+
+// styledRune is a single character of formatted text.
+type styledRune struct {
+	r     rune
+	style TextStyle
+}
+
+// wrapLine breaks the spans into lines no wider than the given width in pixels, preferably at spaces, which are dropped at the breaks.
+// Words wider than a whole line are broken at the last character that fits.
+// As freetype is not thread-safe, the caller must hold the generator's imageWritingLock.
+func wrapLine(face font.Face, spans []TextSpan, width int) [][]TextSpan {
+	var runes []styledRune
+	for _, span := range spans {
+		for _, r := range span.Text {
+			runes = append(runes, styledRune{r: r, style: span.Style})
+		}
+	}
+
+	var lines [][]TextSpan
+	start, current, lastSpace := 0, 0, -1
+	for i := 0; i < len(runes); i++ {
+		advance := spanWidth(face, TextSpan{Text: string(runes[i].r), Style: runes[i].style})
+		if current+advance > width && i > start {
+			end, next := i, i
+			switch {
+			case runes[i].r == ' ':
+				end, next = i, i+1
+			case lastSpace > start:
+				end, next = lastSpace, lastSpace+1
+			}
+			lines = append(lines, joinRunes(runes[start:end]))
+			start, current, lastSpace = next, 0, -1
+			i = next - 1
+			continue
+		}
+		if runes[i].r == ' ' {
+			lastSpace = i
+		}
+		current += advance
+	}
+	return append(lines, joinRunes(runes[start:]))
+}
+
+// joinRunes groups consecutive characters of the same style into spans.
+func joinRunes(runes []styledRune) []TextSpan {
+	var spans []TextSpan
+	for _, r := range runes {
+		if last := len(spans) - 1; last >= 0 && spans[last].Style == r.style {
+			spans[last].Text += string(r.r)
+			continue
+		}
+		spans = append(spans, TextSpan{Text: string(r.r), Style: r.style})
+	}
+	return spans
+}
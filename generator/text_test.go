@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+func TestParseFormatting(t *testing.T) {
+	base := TextStyle{Color: TextColors["gray"], Italic: true}
+	red := TextStyle{Color: TextColors["red"]}
+	tests := []struct {
+		name string
+		text string
+		want []TextSpan
+	}{
+		{"empty", "", nil},
+		{"plain", "Hello", []TextSpan{{Text: "Hello", Style: base}}},
+		{"colour", "§cDanger", []TextSpan{{Text: "Danger", Style: red}}},
+		{"uppercase code", "§CDanger", []TextSpan{{Text: "Danger", Style: red}}},
+		{
+			"formatting adds to the style",
+			"a§lb§nc",
+			[]TextSpan{
+				{Text: "a", Style: base},
+				{Text: "b", Style: TextStyle{Color: base.Color, Italic: true, Bold: true}},
+				{Text: "c", Style: TextStyle{Color: base.Color, Italic: true, Bold: true, Underlined: true}},
+			},
+		},
+		{
+			"colour resets formatting",
+			"§l§mbold§cred",
+			[]TextSpan{{Text: "bold", Style: TextStyle{Color: base.Color, Italic: true, Bold: true, Strikethrough: true}}, {Text: "red", Style: red}},
+		},
+		{"reset to base", "§cred§rbase", []TextSpan{{Text: "red", Style: red}, {Text: "base", Style: base}}},
+		{"obfuscated", "§kx", []TextSpan{{Text: "x", Style: TextStyle{Color: base.Color, Italic: true, Obfuscated: true}}}},
+		{"unknown code is dropped", "a§zb", []TextSpan{{Text: "a", Style: base}, {Text: "b", Style: base}}},
+		{"trailing section sign", "a§", []TextSpan{{Text: "a", Style: base}}},
+		{"only codes", "§c§l§r", nil},
+		{"multibyte text", "§aGrün", []TextSpan{{Text: "Grün", Style: TextStyle{Color: TextColors["green"]}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if spans := ParseFormatting(test.text, base); !reflect.DeepEqual(spans, test.want) {
+				t.Errorf("ParseFormatting(%q) = %+v, want %+v", test.text, spans, test.want)
+			}
+		})
+	}
+}
+
+func TestStripFormatting(t *testing.T) {
+	tests := map[string]string{
+		"":             "",
+		"plain":        "plain",
+		"§cred §lbold": "red bold",
+		"ba§rd":        "bad",
+		"§":            "",
+		"a§§b":         "ab",
+	}
+	for text, want := range tests {
+		if stripped := StripFormatting(text); stripped != want {
+			t.Errorf("StripFormatting(%q) = %q, want %q", text, stripped, want)
+		}
+	}
+}
+
+func TestWrapLine(t *testing.T) {
+	// every glyph of the face is 7 pixels wide
+	face := basicfont.Face7x13
+	plain := TextStyle{Color: TextColors["white"]}
+	bold := TextStyle{Color: TextColors["white"], Bold: true}
+	red := TextStyle{Color: TextColors["red"]}
+	tests := []struct {
+		name  string
+		spans []TextSpan
+		width int
+		want  [][]TextSpan
+	}{
+		{"empty", nil, 70, [][]TextSpan{nil}},
+		{"fits", []TextSpan{{Text: "ab cd", Style: plain}}, 35, [][]TextSpan{{{Text: "ab cd", Style: plain}}}},
+		{
+			"breaks at the last space",
+			[]TextSpan{{Text: "ab cd ef", Style: plain}},
+			35,
+			[][]TextSpan{{{Text: "ab cd", Style: plain}}, {{Text: "ef", Style: plain}}},
+		},
+		{
+			"breaks long words",
+			[]TextSpan{{Text: "abcdefg", Style: plain}},
+			21,
+			[][]TextSpan{{{Text: "abc", Style: plain}}, {{Text: "def", Style: plain}}, {{Text: "g", Style: plain}}},
+		},
+		{
+			"keeps styles",
+			[]TextSpan{{Text: "ab ", Style: plain}, {Text: "cd", Style: red}},
+			21,
+			[][]TextSpan{{{Text: "ab", Style: plain}}, {{Text: "cd", Style: red}}},
+		},
+		{
+			"bold glyphs are wider",
+			[]TextSpan{{Text: "ab", Style: bold}},
+			17,
+			[][]TextSpan{{{Text: "a", Style: bold}}, {{Text: "b", Style: bold}}},
+		},
+		{
+			"narrower than a glyph",
+			[]TextSpan{{Text: "ab", Style: plain}},
+			1,
+			[][]TextSpan{{{Text: "a", Style: plain}}, {{Text: "b", Style: plain}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if lines := wrapLine(face, test.spans, test.width); !reflect.DeepEqual(lines, test.want) {
+				t.Errorf("wrapLine(%+v, %d) = %+v, want %+v", test.spans, test.width, lines, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+
+	"github.com/menzerath/mcgen/assets"
+)
+
+// Rarity selects the colour of an item's name.
+type Rarity string
+
+// Rarity constants.
+const (
+	RarityCommon   Rarity = "common"
+	RarityUncommon Rarity = "uncommon"
+	RarityRare     Rarity = "rare"
+	RarityEpic     Rarity = "epic"
+)
+
+// rarityColors are the names of the colours of each rarity.
+var rarityColors = map[Rarity]string{
+	RarityCommon:   "white",
+	RarityUncommon: "yellow",
+	RarityRare:     "aqua",
+	RarityEpic:     "light_purple",
+}
+
+// limits of tooltips
+const (
+	MaxTooltipLines      = 32
+	MaxTooltipLineLength = 256
+)
+
+// list of errors returned when rendering tooltips
+var (
+	ErrInvalidRarity    = fmt.Errorf("rarity must be common, uncommon, rare or epic")
+	ErrInvalidSlot      = fmt.Errorf("unknown equipment slot")
+	ErrInvalidOperation = fmt.Errorf("operation must be add_value, add_multiplied_base or add_multiplied_total")
+	ErrTooManyLines     = fmt.Errorf("tooltips must not have more than %d lines", MaxTooltipLines)
+	ErrLineTooLong      = fmt.Errorf("lines must not be longer than %d characters", MaxTooltipLineLength)
+)
+
+// layout of tooltips, in pixels of the game's GUI which are doubled like the toast's icon
+const (
+	tooltipPadding = 4
+	tooltipNameGap = 2
+)
+
+// colours of tooltips
+var (
+	tooltipBackground   = color.NRGBA{R: 16, B: 16, A: 240}
+	tooltipBorderTop    = color.NRGBA{R: 80, B: 255, A: 80}
+	tooltipBorderBottom = color.NRGBA{R: 40, B: 127, A: 80}
+)
+
+// equipmentSlots are the headings of attribute modifiers by the slot they apply to.
+var equipmentSlots = map[string]string{
+	"mainhand": "When in Main Hand:",
+	"offhand":  "When in Off Hand:",
+	"head":     "When on Head:",
+	"chest":    "When on Body:",
+	"legs":     "When on Legs:",
+	"feet":     "When on Feet:",
+	"body":     "When on Body:",
+	"hand":     "When held:",
+	"armor":    "When worn:",
+	"any":      "When equipped:",
+}
+
+// A Tooltip describes an item like the game's tooltip shown when hovering it in the inventory.
+// All texts may contain the game's formatting codes.
+type Tooltip struct {
+	Name   string
+	Rarity Rarity
+
+	Enchantments []Enchantment
+	Lore         []string
+	Attributes   []AttributeModifier
+}
+
+// An Enchantment is shown as its name and level, e.g. "Sharpness V"; curses are red.
+type Enchantment struct {
+	ID    string `json:"id"`
+	Level int    `json:"level"`
+}
+
+// An AttributeModifier is shown below the heading of its slot, e.g. "+2 Armor".
+// Base modifiers add to the attribute's base value of the player and are shown like the damage of weapons, e.g. " 7 Attack Damage".
+type AttributeModifier struct {
+	Attribute string  `json:"attribute"`
+	Slot      string  `json:"slot"`
+	Amount    float64 `json:"amount"`
+	Operation string  `json:"operation"`
+	Base      bool    `json:"base"`
+}
+
+// lines returns the formatted lines of the tooltip, starting with its name.
+func (tooltip Tooltip) lines() ([][]TextSpan, error) {
+	rarity := tooltip.Rarity
+	if rarity == "" {
+		rarity = RarityCommon
+	}
+	nameColor, exists := rarityColors[rarity]
+	if !exists {
+		return nil, fmt.Errorf("%w, not %q", ErrInvalidRarity, tooltip.Rarity)
+	}
+
+	lines := [][]TextSpan{ParseFormatting(tooltip.Name, TextStyle{Color: TextColors[nameColor]})}
+	for _, enchantment := range tooltip.Enchantments {
+		lines = append(lines, []TextSpan{enchantmentLine(enchantment)})
+	}
+	for _, lore := range tooltip.Lore {
+		lines = append(lines, ParseFormatting(lore, TextStyle{Color: TextColors["dark_purple"], Italic: true}))
+	}
+
+	// attribute modifiers are grouped by their slot, in order of appearance
+	var slots []string
+	bySlot := make(map[string][]AttributeModifier)
+	for _, modifier := range tooltip.Attributes {
+		slot := modifier.Slot
+		if slot == "" {
+			slot = "mainhand"
+		}
+		if _, exists := equipmentSlots[slot]; !exists {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSlot, modifier.Slot)
+		}
+		if _, exists := bySlot[slot]; !exists {
+			slots = append(slots, slot)
+		}
+		bySlot[slot] = append(bySlot[slot], modifier)
+	}
+	for _, slot := range slots {
+		lines = append(lines, nil, []TextSpan{{Text: equipmentSlots[slot], Style: TextStyle{Color: TextColors["gray"]}}})
+		for _, modifier := range bySlot[slot] {
+			line, err := attributeLine(modifier)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, []TextSpan{line})
+		}
+	}
+
+	if len(lines) > MaxTooltipLines {
+		return nil, fmt.Errorf("%w, not %d", ErrTooManyLines, len(lines))
+	}
+	for _, line := range lines {
+		if length := len([]rune(plainText(line))); length > MaxTooltipLineLength {
+			return nil, fmt.Errorf("%w, not %d", ErrLineTooLong, length)
+		}
+	}
+	return lines, nil
+}
+
+// Text returns the lines of the tooltip as they are shown, without formatting.
+func (tooltip Tooltip) Text() ([]string, error) {
+	lines, err := tooltip.lines()
+	if err != nil {
+		return nil, err
+	}
+	text := make([]string, 0, len(lines))
+	for _, line := range lines {
+		text = append(text, plainText(line))
+	}
+	return text, nil
+}
+
+// enchantmentLine returns the name of the enchantment with its level as roman numeral, which is left out for enchantments with a single level.
+func enchantmentLine(enchantment Enchantment) TextSpan {
+	id := vanillaID(enchantment.ID)
+	style := TextStyle{Color: TextColors["gray"]}
+	name := displayName(id)
+	if curse, isCurse := strings.CutSuffix(id, "_curse"); isCurse {
+		style.Color = TextColors["red"]
+		name = "Curse of " + displayName(curse)
+	}
+	if enchantment.Level > 1 {
+		name += " " + romanNumeral(enchantment.Level)
+	}
+	return TextSpan{Text: name, Style: style}
+}
+
+// attributeLine returns the modifier's amount and attribute: base values in dark green, bonuses in blue and penalties in red.
+func attributeLine(modifier AttributeModifier) (TextSpan, error) {
+	amount := modifier.Amount
+	unit := ""
+	switch modifier.Operation {
+	case "", "add_value":
+	case "add_multiplied_base", "add_multiplied_total":
+		amount *= 100
+		unit = "%"
+	default:
+		return TextSpan{}, fmt.Errorf("%w, not %q", ErrInvalidOperation, modifier.Operation)
+	}
+	name := displayName(strings.TrimPrefix(vanillaID(modifier.Attribute), "generic."))
+	number := strconv.FormatFloat(amount, 'f', -1, 64)
+
+	switch {
+	case modifier.Base:
+		return TextSpan{Text: " " + number + unit + " " + name, Style: TextStyle{Color: TextColors["dark_green"]}}, nil
+	case amount < 0:
+		return TextSpan{Text: number + unit + " " + name, Style: TextStyle{Color: TextColors["red"]}}, nil
+	}
+	return TextSpan{Text: "+" + number + unit + " " + name, Style: TextStyle{Color: TextColors["blue"]}}, nil
+}
+
+// displayName turns an ID into the English name the game shows, e.g. "attack_damage" into "Attack Damage".
+func displayName(id string) string {
+	words := strings.Split(id, "_")
+	for i, word := range words {
+		if word != "" && (i == 0 || (word != "of" && word != "the")) {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// romanNumeral returns the game's translation of an enchantment's level: roman numerals up to 10, plain numbers above.
+func romanNumeral(level int) string {
+	numerals := []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X"}
+	if level >= 1 && level <= len(numerals) {
+		return numerals[level-1]
+	}
+	return strconv.Itoa(level)
+}
+
+// GenerateTooltip renders the tooltip of an item on a transparent background and returns it as PNG.
+// It will return an error if the tooltip is invalid, the generator is overloaded or the context is cancelled while waiting.
+func (generator *Generator) GenerateTooltip(ctx context.Context, tooltip Tooltip) ([]byte, error) {
+	lines, err := tooltip.lines()
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := generator.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	img, err := generator.renderTooltip(lines)
+	if err != nil {
+		return nil, err
+	}
+	return encodePNG(img)
+}
+
+// renderTooltip draws the lines on the tooltip's background; the first one is set apart as the item's name.
+func (generator *Generator) renderTooltip(lines [][]TextSpan) (image.Image, error) {
+	// we need to lock this because the freetype library is not thread-safe
+	generator.imageWritingLock.Lock()
+	defer generator.imageWritingLock.Unlock()
+
+	face, err := generator.fontFace(assets.DefaultFont)
+	if err != nil {
+		return nil, err
+	}
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, lineWidth(face, line))
+	}
+	// the text's size in GUI pixels, rounded up
+	contentWidth := (width + iconScale - 1) / iconScale
+	contentHeight := glyphHeight + 1 + (len(lines)-1)*lineHeight
+	if len(lines) > 1 {
+		contentHeight += tooltipNameGap
+	}
+
+	content := image.Rect(0, 0, contentWidth, contentHeight).Add(image.Pt(tooltipPadding, tooltipPadding))
+	img := image.NewNRGBA(image.Rectangle{Max: content.Max.Add(image.Pt(tooltipPadding, tooltipPadding)).Mul(iconScale)})
+	drawTooltipBackground(img, content)
+
+	y := content.Min.Y + glyphHeight
+	for i, line := range lines {
+		drawLine(img, face, line, image.Pt(content.Min.X, y).Mul(iconScale), true)
+		y += lineHeight
+		if i == 0 {
+			y += tooltipNameGap
+		}
+	}
+	return img, nil
+}
+
+// drawTooltipBackground draws the game's tooltip around the given content in GUI pixels: a dark background with rounded corners
+// and a purple border fading from top to bottom.
+func drawTooltipBackground(img *image.NRGBA, content image.Rectangle) {
+	outer := content.Inset(-3)
+	fillGUI(img, outer, tooltipBackground)
+	fillGUI(img, image.Rect(outer.Min.X, outer.Min.Y-1, outer.Max.X, outer.Min.Y), tooltipBackground)
+	fillGUI(img, image.Rect(outer.Min.X, outer.Max.Y, outer.Max.X, outer.Max.Y+1), tooltipBackground)
+	fillGUI(img, image.Rect(outer.Min.X-1, outer.Min.Y, outer.Min.X, outer.Max.Y), tooltipBackground)
+	fillGUI(img, image.Rect(outer.Max.X, outer.Min.Y, outer.Max.X+1, outer.Max.Y), tooltipBackground)
+
+	border := outer.Inset(1)
+	borderGradient(img, image.Rect(outer.Min.X, border.Min.Y, border.Min.X, border.Max.Y), border)
+	borderGradient(img, image.Rect(border.Max.X, border.Min.Y, outer.Max.X, border.Max.Y), border)
+	draw.Draw(img, scaleGUI(image.Rect(outer.Min.X, outer.Min.Y, outer.Max.X, border.Min.Y)), image.NewUniform(tooltipBorderTop), image.Point{}, draw.Over)
+	draw.Draw(img, scaleGUI(image.Rect(outer.Min.X, border.Max.Y, outer.Max.X, outer.Max.Y)), image.NewUniform(tooltipBorderBottom), image.Point{}, draw.Over)
+}
+
+// borderGradient draws a side of the border over the background, fading between the top and bottom colour over the height of the given bounds.
+func borderGradient(img *image.NRGBA, rect image.Rectangle, bounds image.Rectangle) {
+	scaled := scaleGUI(rect)
+	height := scaleGUI(bounds).Dy()
+	for y := scaled.Min.Y; y < scaled.Max.Y; y++ {
+		t := float64(y-scaled.Min.Y) / float64(max(height-1, 1))
+		c := color.NRGBA{
+			R: uint8(float64(tooltipBorderTop.R)*(1-t) + float64(tooltipBorderBottom.R)*t),
+			G: uint8(float64(tooltipBorderTop.G)*(1-t) + float64(tooltipBorderBottom.G)*t),
+			B: uint8(float64(tooltipBorderTop.B)*(1-t) + float64(tooltipBorderBottom.B)*t),
+			A: tooltipBorderTop.A,
+		}
+		draw.Draw(img, image.Rect(scaled.Min.X, y, scaled.Max.X, y+1), image.NewUniform(c), image.Point{}, draw.Over)
+	}
+}
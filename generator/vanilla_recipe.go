@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidRecipe is returned for recipe files that cannot be shown.
+var ErrInvalidRecipe = fmt.Errorf("invalid recipe")
+
+// vanillaRecipe is a recipe file of the game's data packs, covering the formats of all recent versions.
+type vanillaRecipe struct {
+	Type string `json:"type"`
+
+	// crafting_shaped
+	Pattern []string                   `json:"pattern"`
+	Key     map[string]json.RawMessage `json:"key"`
+
+	// crafting_shapeless
+	Ingredients []json.RawMessage `json:"ingredients"`
+
+	// smelting, blasting, smoking and campfire_cooking
+	Ingredient json.RawMessage `json:"ingredient"`
+
+	// smithing_transform
+	Template json.RawMessage `json:"template"`
+	Base     json.RawMessage `json:"base"`
+	Addition json.RawMessage `json:"addition"`
+
+	Result json.RawMessage `json:"result"`
+}
+
+// ParseRecipe parses a recipe file of the game, e.g. data/minecraft/recipe/torch.json.
+// Shaped and shapeless crafting recipes are shown on a crafting table, cooking recipes in a furnace and smithing transforms on a smithing table.
+// Ingredients accepting several items show the first one; tags are shown as the item or background named like them.
+func ParseRecipe(data []byte) (Recipe, error) {
+	var file vanillaRecipe
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Recipe{}, fmt.Errorf("%w: %w", ErrInvalidRecipe, err)
+	}
+
+	var recipe Recipe
+	var err error
+	switch vanillaID(file.Type) {
+	case "crafting_shaped":
+		recipe.Type = RecipeCrafting
+		for _, line := range file.Pattern {
+			row := make([]string, 0, len(line))
+			for _, symbol := range line {
+				if symbol == ' ' {
+					row = append(row, "")
+					continue
+				}
+				key, exists := file.Key[string(symbol)]
+				if !exists {
+					return Recipe{}, fmt.Errorf("%w: undefined key %q", ErrInvalidRecipe, symbol)
+				}
+				item, err := ingredient(key)
+				if err != nil {
+					return Recipe{}, err
+				}
+				row = append(row, item)
+			}
+			recipe.Grid = append(recipe.Grid, row)
+		}
+	case "crafting_shapeless":
+		recipe.Type = RecipeCrafting
+		if len(file.Ingredients) > 9 {
+			return Recipe{}, fmt.Errorf("%w: %d ingredients", ErrInvalidRecipe, len(file.Ingredients))
+		}
+		// ingredients fill the grid row by row
+		for i, raw := range file.Ingredients {
+			item, err := ingredient(raw)
+			if err != nil {
+				return Recipe{}, err
+			}
+			if i%3 == 0 {
+				recipe.Grid = append(recipe.Grid, nil)
+			}
+			recipe.Grid[i/3] = append(recipe.Grid[i/3], item)
+		}
+	case "smelting", "blasting", "smoking", "campfire_cooking":
+		recipe.Type = RecipeFurnace
+		item, err := ingredient(file.Ingredient)
+		if err != nil {
+			return Recipe{}, err
+		}
+		recipe.Grid = [][]string{{item}}
+	case "smithing_transform":
+		recipe.Type = RecipeSmithing
+		row := make([]string, 0, 3)
+		for _, raw := range []json.RawMessage{file.Template, file.Base, file.Addition} {
+			item, err := ingredient(raw)
+			if err != nil {
+				return Recipe{}, err
+			}
+			row = append(row, item)
+		}
+		recipe.Grid = [][]string{row}
+	default:
+		return Recipe{}, fmt.Errorf("%w: unsupported type %q", ErrInvalidRecipe, file.Type)
+	}
+
+	recipe.Result, recipe.Count, err = recipeResult(file.Result)
+	if err != nil {
+		return Recipe{}, err
+	}
+	return recipe, nil
+}
+
+// ingredient returns the item shown for an ingredient, which is given as item ID or "#tag", as object with "item" or "tag", or as list of alternatives.
+func ingredient(raw json.RawMessage) (string, error) {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return strings.TrimPrefix(id, "#"), nil
+	}
+
+	var object struct {
+		Item string `json:"item"`
+		Tag  string `json:"tag"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil && (object.Item != "" || object.Tag != "") {
+		if object.Item != "" {
+			return object.Item, nil
+		}
+		return object.Tag, nil
+	}
+
+	var alternatives []json.RawMessage
+	if err := json.Unmarshal(raw, &alternatives); err == nil && len(alternatives) > 0 {
+		return ingredient(alternatives[0])
+	}
+	return "", fmt.Errorf("%w: invalid ingredient %s", ErrInvalidRecipe, raw)
+}
+
+// recipeResult returns the item and count of a result, which is given as item ID or as object with "id" or "item" and an optional "count".
+func recipeResult(raw json.RawMessage) (string, int, error) {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id, 0, nil
+	}
+
+	var object struct {
+		ID    string `json:"id"`
+		Item  string `json:"item"`
+		Count int    `json:"count"`
+	}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return "", 0, fmt.Errorf("%w: invalid result %s", ErrInvalidRecipe, raw)
+	}
+	if object.ID == "" {
+		object.ID = object.Item
+	}
+	return object.ID, object.Count, nil
+}
@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseRecipe(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Recipe
+	}{
+		{
+			"shaped with tags and spaces",
+			`{"type":"minecraft:crafting_shaped","pattern":["# ","##"],"key":{"#":"#minecraft:planks"},"result":{"id":"minecraft:crafting_table","count":1}}`,
+			Recipe{Type: RecipeCrafting, Grid: [][]string{{"minecraft:planks", ""}, {"minecraft:planks", "minecraft:planks"}}, Result: "minecraft:crafting_table", Count: 1},
+		},
+		{
+			"shaped with legacy keys",
+			`{"type":"crafting_shaped","pattern":["X","/"],"key":{"X":{"item":"minecraft:coal"},"/":{"tag":"minecraft:sticks"}},"result":{"item":"minecraft:torch","count":4}}`,
+			Recipe{Type: RecipeCrafting, Grid: [][]string{{"minecraft:coal"}, {"minecraft:sticks"}}, Result: "minecraft:torch", Count: 4},
+		},
+		{
+			"shapeless fills rows",
+			`{"type":"minecraft:crafting_shapeless","ingredients":["a","b","c","d"],"result":"minecraft:e"}`,
+			Recipe{Type: RecipeCrafting, Grid: [][]string{{"a", "b", "c"}, {"d"}}, Result: "minecraft:e"},
+		},
+		{
+			"alternatives show the first",
+			`{"type":"minecraft:crafting_shapeless","ingredients":[[{"item":"a"},{"item":"b"}]],"result":{"id":"c"}}`,
+			Recipe{Type: RecipeCrafting, Grid: [][]string{{"a"}}, Result: "c"},
+		},
+		{
+			"cooking",
+			`{"type":"minecraft:blasting","ingredient":"minecraft:iron_ore","result":{"id":"minecraft:iron_ingot"}}`,
+			Recipe{Type: RecipeFurnace, Grid: [][]string{{"minecraft:iron_ore"}}, Result: "minecraft:iron_ingot"},
+		},
+		{
+			"smithing",
+			`{"type":"minecraft:smithing_transform","template":"t","base":"b","addition":"#a","result":{"id":"r"}}`,
+			Recipe{Type: RecipeSmithing, Grid: [][]string{{"t", "b", "a"}}, Result: "r"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recipe, err := ParseRecipe([]byte(test.data))
+			if err != nil {
+				t.Fatalf("ParseRecipe returned %v", err)
+			}
+			if !reflect.DeepEqual(recipe, test.want) {
+				t.Errorf("ParseRecipe = %+v, want %+v", recipe, test.want)
+			}
+		})
+	}
+}
+
+func TestParseRecipeInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"no json", `crafting_shaped`},
+		{"no object", `[]`},
+		{"unsupported type", `{"type":"minecraft:stonecutting","ingredient":"a","result":"b"}`},
+		{"missing type", `{"ingredient":"a","result":"b"}`},
+		{"undefined key", `{"type":"crafting_shaped","pattern":["#X"],"key":{"#":"a"},"result":"b"}`},
+		{"invalid key", `{"type":"crafting_shaped","pattern":["#"],"key":{"#":42},"result":"b"}`},
+		{"too many ingredients", `{"type":"crafting_shapeless","ingredients":["a","a","a","a","a","a","a","a","a","a"],"result":"b"}`},
+		{"empty alternatives", `{"type":"crafting_shapeless","ingredients":[[]],"result":"b"}`},
+		{"empty ingredient object", `{"type":"crafting_shapeless","ingredients":[{}],"result":"b"}`},
+		{"missing ingredient", `{"type":"smelting","result":"b"}`},
+		{"missing smithing base", `{"type":"smithing_transform","template":"t","addition":"a","result":"r"}`},
+		{"missing result", `{"type":"smelting","ingredient":"a"}`},
+		{"invalid result", `{"type":"smelting","ingredient":"a","result":["b"]}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if recipe, err := ParseRecipe([]byte(test.data)); !errors.Is(err, ErrInvalidRecipe) {
+				t.Errorf("ParseRecipe = %+v, %v, want %v", recipe, err, ErrInvalidRecipe)
+			}
+		})
+	}
+}
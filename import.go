@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image/png"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/menzerath/mcgen/assets"
+)
+
+// importResourcePack converts the item and block textures of a Minecraft resource pack into one asset pack per namespace.
+// It returns the exit code of the command.
+func importResourcePack(arguments []string) int {
+	flags := flag.NewFlagSet("mcgen import-resource-pack", flag.ContinueOnError)
+	output := flags.String("out", "packs", "directory to write one asset pack per namespace to")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: mcgen import-resource-pack [-out directory] <resource pack>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(arguments); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	packs, err := assets.LoadResourcePack(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	for _, pack := range packs {
+		dir := filepath.Join(*output, pack.Namespace)
+		if err := writePack(pack, dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("imported %d icons and %d blocks of namespace %s to %s\n", len(pack.Icons), len(pack.Blocks), pack.Namespace, dir)
+	}
+
+	namespaces := make([]string, 0, len(packs))
+	for _, pack := range packs {
+		namespaces = append(namespaces, pack.Namespace+"="+filepath.Join(*output, pack.Namespace))
+	}
+	fmt.Printf("use them by setting ASSET_PACKS=%s\n", strings.Join(namespaces, ","))
+	return 0
+}
+
+// writePack writes the icons and blocks of the pack to the given directory, in the layout asset packs are loaded from.
+func writePack(pack assets.Pack, dir string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "icons"), 0o755); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(pack.Icons)) {
+		file, err := os.Create(filepath.Join(dir, "icons", name+".png"))
+		if err != nil {
+			return err
+		}
+		err = png.Encode(file, pack.Icons[name])
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("writing icon %s: %w", name, err)
+		}
+	}
+
+	if len(pack.Blocks) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "blocks"), 0o755); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(pack.Blocks)) {
+		content, err := json.Marshal(pack.Blocks[name])
+		if err != nil {
+			return fmt.Errorf("writing block %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "blocks", name+".json"), append(content, '\n'), 0o644); err != nil {
+			return fmt.Errorf("writing block %s: %w", name, err)
+		}
+	}
+	return nil
+}
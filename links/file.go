@@ -0,0 +1,153 @@
+package links
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileStore is a Store keeping all links in memory and appending new ones to a JSON log on disk.
+type FileStore struct {
+	path  string
+	file  *os.File
+	links map[string]Link
+
+	lock sync.RWMutex
+}
+
+// OpenFileStore opens the JSON log at the given path, creating it if necessary.
+// Expired links are dropped from the log while opening it.
+func OpenFileStore(path string) (*FileStore, error) {
+	store := &FileStore{
+		path:  path,
+		links: make(map[string]Link),
+	}
+
+	expired, err := store.load()
+	if err != nil {
+		return nil, err
+	}
+	if expired > 0 {
+		if err := store.compact(); err != nil {
+			return nil, err
+		}
+	}
+
+	store.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening links file: %w", err)
+	}
+	slog.Debug("loaded links", "path", path, "count", len(store.links), "expired", expired)
+
+	return store, nil
+}
+
+// load reads all links from the log and returns the number of expired links.
+func (store *FileStore) load() (int, error) {
+	file, err := os.Open(store.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("opening links file: %w", err)
+	}
+	defer file.Close()
+
+	now := time.Now()
+	expired := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var link Link
+		if err := json.Unmarshal(scanner.Bytes(), &link); err != nil {
+			return 0, fmt.Errorf("decoding links file line %d: %w", line, err)
+		}
+		if link.Expired(now) {
+			expired++
+			continue
+		}
+		store.links[link.ID] = link
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("reading links file: %w", err)
+	}
+
+	return expired, nil
+}
+
+// compact rewrites the log to only contain the links currently held in memory.
+func (store *FileStore) compact() error {
+	temporaryPath := store.path + ".tmp"
+	file, err := os.OpenFile(temporaryPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating compacted links file: %w", err)
+	}
+
+	encoder := json.NewEncoder(file)
+	for _, link := range store.links {
+		if err := encoder.Encode(link); err != nil {
+			file.Close()
+			return fmt.Errorf("writing compacted links file: %w", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("writing compacted links file: %w", err)
+	}
+
+	if err := os.Rename(temporaryPath, store.path); err != nil {
+		return fmt.Errorf("replacing links file: %w", err)
+	}
+	return nil
+}
+
+// Create stores a new link and returns ErrExists if its ID is already taken.
+func (store *FileStore) Create(link Link) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if existing, exists := store.links[link.ID]; exists && !existing.Expired(time.Now()) {
+		return ErrExists
+	}
+
+	content, err := json.Marshal(link)
+	if err != nil {
+		return fmt.Errorf("encoding link: %w", err)
+	}
+	if _, err := store.file.Write(append(content, '\n')); err != nil {
+		return fmt.Errorf("writing link: %w", err)
+	}
+	if err := store.file.Sync(); err != nil {
+		return fmt.Errorf("syncing links file: %w", err)
+	}
+
+	store.links[link.ID] = link
+	return nil
+}
+
+// Get returns the link with the given ID and ErrNotFound if it does not exist or has expired.
+func (store *FileStore) Get(id string) (Link, error) {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	link, exists := store.links[id]
+	if !exists || link.Expired(time.Now()) {
+		return Link{}, ErrNotFound
+	}
+	return link, nil
+}
+
+// Close closes the underlying file.
+func (store *FileStore) Close() error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	return store.file.Close()
+}
@@ -0,0 +1,127 @@
+package links
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openStore(t *testing.T, path string) *FileStore {
+	t.Helper()
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore returned %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	store := openStore(t, path)
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	links := []Link{
+		{ID: "forever", Request: json.RawMessage(`{"title":"a"}`), CreatedAt: time.Now().UTC()},
+		{ID: "later", Request: json.RawMessage(`{"title":"b"}`), CreatedAt: time.Now().UTC(), ExpiresAt: &future},
+		{ID: "expired", Request: json.RawMessage(`{"title":"c"}`), CreatedAt: time.Now().UTC(), ExpiresAt: &past},
+	}
+	for _, link := range links {
+		if err := store.Create(link); err != nil {
+			t.Fatalf("Create(%s) returned %v", link.ID, err)
+		}
+	}
+
+	// requests by ID; links that cannot be found are empty
+	want := map[string]string{"forever": `{"title":"a"}`, "later": `{"title":"b"}`, "expired": "", "unknown": ""}
+	check := func(store *FileStore) {
+		t.Helper()
+		for id, request := range want {
+			link, err := store.Get(id)
+			if request == "" && !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get(%s) returned %v, want %v", id, err, ErrNotFound)
+			}
+			if request != "" && err != nil {
+				t.Errorf("Get(%s) returned %v", id, err)
+			}
+			if string(link.Request) != request {
+				t.Errorf("Get(%s) = %s, want %s", id, link.Request, request)
+			}
+		}
+	}
+	check(store)
+
+	if err := store.Create(Link{ID: "forever"}); !errors.Is(err, ErrExists) {
+		t.Errorf("Create of a taken ID returned %v, want %v", err, ErrExists)
+	}
+	// expired IDs may be taken again
+	if err := store.Create(Link{ID: "expired", Request: json.RawMessage(`{"title":"d"}`)}); err != nil {
+		t.Errorf("Create of an expired ID returned %v", err)
+	}
+	want["expired"] = `{"title":"d"}`
+	check(store)
+
+	// reopening restores all links from the log
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close returned %v", err)
+	}
+	check(openStore(t, path))
+}
+
+func TestFileStoreCompactsExpiredLinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	past := time.Now().Add(-time.Hour)
+	store := openStore(t, path)
+	for _, link := range []Link{{ID: "kept", Request: json.RawMessage(`{}`)}, {ID: "dropped", Request: json.RawMessage(`{}`), ExpiresAt: &past}} {
+		if err := store.Create(link); err != nil {
+			t.Fatalf("Create(%s) returned %v", link.ID, err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close returned %v", err)
+	}
+
+	openStore(t, path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading log: %v", err)
+	}
+	if !strings.Contains(string(content), `"kept"`) || strings.Contains(string(content), `"dropped"`) {
+		t.Errorf("compacted log = %s, want only the link that has not expired", content)
+	}
+}
+
+func TestOpenFileStoreInvalid(t *testing.T) {
+	tests := map[string]string{
+		"no json":    "links\n",
+		"truncated":  `{"id":"a","request":{}}` + "\n" + `{"id":"b",`,
+		"wrong type": `{"id":42}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "links.json")
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if store, err := OpenFileStore(path); err == nil {
+				_ = store.Close()
+				t.Errorf("OpenFileStore accepted %q", content)
+			}
+		})
+	}
+}
+
+func TestOpenFileStoreSkipsEmptyLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	if err := os.WriteFile(path, []byte("\n"+`{"id":"a","request":{}}`+"\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openStore(t, path).Get("a"); err != nil {
+		t.Errorf("Get(a) returned %v", err)
+	}
+}
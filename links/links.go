@@ -0,0 +1,77 @@
+// Package links stores achievement requests under short IDs.
+package links
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// list of errors returned by stores
+var (
+	ErrNotFound    = fmt.Errorf("link not found")
+	ErrExists      = fmt.Errorf("link already exists")
+	ErrInvalidSlug = fmt.Errorf("invalid slug")
+)
+
+// slugPattern restricts custom slugs to characters that are safe to use in URLs.
+var slugPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{3,64}$`)
+
+// the alphabet and length used for generated IDs
+const (
+	idAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	idLength   = 7
+)
+
+// A Link maps a short ID to a stored request.
+type Link struct {
+	ID        string          `json:"id"`
+	Request   json.RawMessage `json:"request"`
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+}
+
+// Expired reports whether the link has expired at the given time.
+func (link Link) Expired(now time.Time) bool {
+	return link.ExpiresAt != nil && !now.Before(*link.ExpiresAt)
+}
+
+// A Store persists links.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Create stores a new link and returns ErrExists if its ID is already taken.
+	Create(link Link) error
+
+	// Get returns the link with the given ID and ErrNotFound if it does not exist or has expired.
+	Get(id string) (Link, error)
+
+	// Close releases all resources held by the store.
+	Close() error
+}
+
+// ValidateSlug returns ErrInvalidSlug if the given custom slug cannot be used as an ID.
+func ValidateSlug(slug string) error {
+	if !slugPattern.MatchString(slug) {
+		return fmt.Errorf("%w: must be 3 to 64 letters, digits, dashes or underscores", ErrInvalidSlug)
+	}
+	return nil
+}
+
+// NewID returns a new random ID, in which all characters of the alphabet are equally likely.
+func NewID() string {
+	// bytes beyond the largest multiple of the alphabet's length are rejected, as they would favour its first characters
+	limit := 256 - 256%len(idAlphabet)
+	id := make([]byte, 0, idLength)
+	random := make([]byte, idLength)
+	for len(id) < idLength {
+		_, _ = rand.Read(random)
+		for _, b := range random {
+			if int(b) < limit && len(id) < idLength {
+				id = append(id, idAlphabet[int(b)%len(idAlphabet)])
+			}
+		}
+	}
+	return string(id)
+}
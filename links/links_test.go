@@ -0,0 +1,72 @@
+package links
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateSlug(t *testing.T) {
+	tests := []struct {
+		slug  string
+		valid bool
+	}{
+		{"abc", true},
+		{"my-server_2024", true},
+		{strings.Repeat("a", 64), true},
+		{"", false},
+		{"ab", false},
+		{strings.Repeat("a", 65), false},
+		{"with space", false},
+		{"slash/es", false},
+		{"dots.", false},
+		{"ümlaut", false},
+	}
+	for _, test := range tests {
+		err := ValidateSlug(test.slug)
+		if test.valid && err != nil {
+			t.Errorf("ValidateSlug(%q) returned %v", test.slug, err)
+		}
+		if !test.valid && !errors.Is(err, ErrInvalidSlug) {
+			t.Errorf("ValidateSlug(%q) = %v, want %v", test.slug, err, ErrInvalidSlug)
+		}
+	}
+}
+
+func TestNewID(t *testing.T) {
+	for range 100 {
+		id := NewID()
+		if len(id) != idLength {
+			t.Fatalf("NewID() = %q, want %d characters", id, idLength)
+		}
+		for _, r := range id {
+			if !strings.ContainsRune(idAlphabet, r) {
+				t.Fatalf("NewID() = %q contains %q", id, r)
+			}
+		}
+		if err := ValidateSlug(id); err != nil {
+			t.Fatalf("NewID() = %q is no valid slug: %v", id, err)
+		}
+	}
+}
+
+func TestLinkExpired(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Second), now.Add(time.Second)
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		want      bool
+	}{
+		{"never", nil, false},
+		{"past", &past, true},
+		{"now", &now, true},
+		{"future", &future, false},
+	}
+	for _, test := range tests {
+		if expired := (Link{ExpiresAt: test.expiresAt}).Expired(now); expired != test.want {
+			t.Errorf("%s: Expired = %v, want %v", test.name, expired, test.want)
+		}
+	}
+}
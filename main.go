@@ -2,10 +2,19 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/menzerath/mcgen/assets"
+	"github.com/menzerath/mcgen/config"
+	"github.com/menzerath/mcgen/filter"
 	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/links"
 	"github.com/menzerath/mcgen/metrics"
 	"github.com/menzerath/mcgen/web"
 )
@@ -16,32 +25,155 @@ var (
 )
 
 func main() {
-	initLogging()
+	if len(os.Args) > 1 && os.Args[1] == "import-resource-pack" {
+		os.Exit(importResourcePack(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "render-chat" {
+		os.Exit(renderChat(os.Args[2:]))
+	}
+
+	cfg, printConfig, err := config.Load(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		slog.Error("loading configuration", "error", err)
+		os.Exit(2)
+	}
+	if printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			slog.Error("printing configuration", "error", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(cfg.LogLevel())
+	slog.SetDefault(slog.New(cfg.LogHandler(os.Stderr, logLevel)))
 	slog.Info("starting mcgen", slog.Group("build", "ref", commitRef, "hash", commitHash))
 
-	go metrics.ExposeMetrics()
+	if cfg.Metrics.Enabled {
+		go metrics.ExposeMetrics(cfg.Metrics.Listen)
+	}
+
+	generatorOptions := cfg.GeneratorOptions()
+	generatorOptions.Packs, err = cfg.Assets.LoadPacks()
+	if err != nil {
+		slog.Error("loading asset packs", "error", err)
+		os.Exit(1)
+	}
 
-	gen, err := generator.New()
+	gen, err := generator.New(generatorOptions)
 	if err != nil {
 		slog.Error("initializing generator", "error", err)
 		os.Exit(1)
 	}
 
 	webAPI := web.New(gen)
+	webAPI.Build = commitHash
+	cfg.ConfigureWebAPI(&webAPI)
+
+	// short links are only available if a file to store them in is configured
+	if cfg.Links.File != "" {
+		linkStore, err := links.OpenFileStore(cfg.Links.File)
+		if err != nil {
+			slog.Error("opening links store", "error", err)
+			os.Exit(1)
+		}
+		defer linkStore.Close()
+		webAPI.Links = linkStore
+	}
+
+	if cfg.APIKeys.File != "" {
+		keys, err := web.LoadAPIKeys(cfg.APIKeys.File)
+		if err != nil {
+			slog.Error("loading api keys", "error", err)
+			os.Exit(1)
+		}
+		webAPI.APIKeys.Keys = append(webAPI.APIKeys.Keys, keys...)
+		if err := web.ValidateAPIKeys(webAPI.APIKeys.Keys); err != nil {
+			slog.Error("loading api keys", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if cfg.Filter.File != "" {
+		webAPI.Filter, err = filter.Load(cfg.Filter.File)
+		if err != nil {
+			slog.Error("loading content filter", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// settings from the settings file take precedence and are reloaded on SIGHUP
+	if cfg.SettingsFile != "" {
+		settings, err := loadSettings(cfg, gen)
+		if err != nil {
+			slog.Error("loading settings", "error", err)
+			os.Exit(1)
+		}
+		webAPI.Server = settings.HTTP.Server()
+		applySettings(settings, logLevel, gen)
+
+		hangup := make(chan os.Signal, 1)
+		signal.Notify(hangup, syscall.SIGHUP)
+		go func() {
+			for range hangup {
+				if err := reloadSettings(cfg, logLevel, gen, webAPI); err != nil {
+					metrics.ReloadFailures.Inc()
+					slog.Error("reloading settings failed, keeping current settings", "error", err)
+					continue
+				}
+				slog.Info("reloaded settings", "path", cfg.SettingsFile)
+			}
+		}()
+	}
+
 	webAPI.StartWebAPI()
 }
 
-func initLogging() {
-	var handler slog.Handler
-	if os.Getenv("MODE") == "production" {
-		handler = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
-			Level: slog.LevelInfo,
-		})
-	} else {
-		handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-			AddSource: true,
-			Level:     slog.LevelDebug,
-		})
-	}
-	slog.SetDefault(slog.New(handler))
+// loadSettings loads the settings file and checks that all legacy icons and item IDs are mapped to existing backgrounds.
+func loadSettings(cfg config.Config, gen *generator.Generator) (config.Settings, error) {
+	settings, err := config.LoadSettings(cfg.SettingsFile, cfg)
+	if err != nil {
+		return config.Settings{}, err
+	}
+	for id, background := range settings.LegacyIcons {
+		if !gen.HasBackground(background) {
+			return config.Settings{}, fmt.Errorf("legacy icon %s: unknown background %q", id, background)
+		}
+	}
+	for id, background := range settings.ItemIcons {
+		if _, exists := gen.Assets.Background(background); !exists {
+			return config.Settings{}, fmt.Errorf("item icon %s: unknown background %q", id, background)
+		}
+	}
+	return settings, nil
+}
+
+// applySettings applies all settings not related to the web api's server.
+// The generator's colours and icon mappings are built first and replaced at once, so no generation sees a mix of old and new ones.
+func applySettings(settings config.Settings, logLevel *slog.LevelVar, gen *generator.Generator) {
+	mappings := assets.NewIconMappings(settings.LegacyIcons, settings.ItemIcons)
+	gen.Reload(settings.Generator.TitleColor.RGBA(), settings.Generator.TextColor.RGBA(), mappings)
+
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(settings.Log.Level))
+	logLevel.Set(level)
+}
+
+// reloadSettings reloads the settings file and applies it.
+// Invalid settings are not applied at all; the server is replaced first, as it is the only part that can fail.
+func reloadSettings(cfg config.Config, logLevel *slog.LevelVar, gen *generator.Generator, webAPI web.WebAPI) error {
+	settings, err := loadSettings(cfg, gen)
+	if err != nil {
+		return err
+	}
+	if err := webAPI.ReloadServer(settings.HTTP.Server()); err != nil {
+		return fmt.Errorf("reloading server: %w", err)
+	}
+
+	applySettings(settings, logLevel, gen)
+	return nil
 }
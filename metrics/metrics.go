@@ -2,6 +2,7 @@
 package metrics
 
 import (
+	"log/slog"
 	"net/http"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,8 +13,30 @@ import (
 const (
 	namespace          = "mcgen"
 	subsystemGenerator = "generator"
+	subsystemWeb       = "web"
+	subsystemFilter    = "filter"
+	subsystemConfig    = "config"
 )
 
+// runtimeBuckets are the buckets of the generator's runtimes in seconds.
+var runtimeBuckets = []float64{
+	0.0001, // 100µs
+	0.0002,
+	0.0005,
+	0.001, // 1ms
+	0.002,
+	0.005,
+	0.01, // 10ms
+	0.02,
+	0.05,
+	0.1, // 100 ms
+	0.2,
+	0.5,
+	1.0, // 1s
+	2.0,
+	5.0,
+}
+
 // all our metrics
 var (
 	AchievementGenerationRuntime = promauto.NewHistogram(prometheus.HistogramOpts{
@@ -21,28 +44,71 @@ var (
 		Subsystem: subsystemGenerator,
 		Name:      "runtime",
 		Help:      "How long it took to generate an achievement image in seconds.",
-		Buckets: []float64{
-			0.0001, // 100µs
-			0.0002,
-			0.0005,
-			0.001, // 1ms
-			0.002,
-			0.005,
-			0.01, // 10ms
-			0.02,
-			0.05,
-			0.1, // 100 ms
-			0.2,
-			0.5,
-			1.0, // 1s
-			2.0,
-			5.0,
-		},
+		Buckets:   runtimeBuckets,
+	})
+
+	RenderRuntime = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystemGenerator,
+		Name:      "render_runtime",
+		Help:      "How long it took to render images other than achievements in seconds, by kind of image.",
+		Buckets:   runtimeBuckets,
+	}, []string{"kind"})
+
+	GeneratorInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystemGenerator,
+		Name:      "in_flight",
+		Help:      "Number of images currently being generated.",
+	})
+
+	GeneratorQueued = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystemGenerator,
+		Name:      "queued",
+		Help:      "Number of generations waiting for a free slot.",
+	})
+
+	SignatureRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemWeb,
+		Name:      "signature_rejections_total",
+		Help:      "Total number of requests to protected routes rejected because of a missing, expired or invalid signature.",
+	}, []string{"reason"})
+
+	RateLimitedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemWeb,
+		Name:      "rate_limited_requests_total",
+		Help:      "Total number of requests rejected because the client exceeded its rate limit.",
+	}, []string{"path"})
+
+	APIKeyRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemWeb,
+		Name:      "api_key_requests_total",
+		Help:      "Total number of requests per API key, by whether they were accepted or rejected.",
+	}, []string{"key", "result"})
+
+	FilterHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemFilter,
+		Name:      "hits_total",
+		Help:      "Total number of texts matching a content filter rule, by rule and action.",
+	}, []string{"rule", "action"})
+
+	ReloadFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemConfig,
+		Name:      "reload_failures_total",
+		Help:      "Total number of failed reloads of the settings file.",
 	})
 )
 
-// ExposeMetrics starts a http server to serve prometheus metrics
-func ExposeMetrics() {
+// ExposeMetrics starts a http server on the given address to serve prometheus metrics
+func ExposeMetrics(address string) {
 	http.Handle("/metrics", promhttp.Handler())
-	http.ListenAndServe(":9100", nil)
+	if err := http.ListenAndServe(address, nil); err != nil {
+		slog.Error("exposing metrics", "error", err)
+	}
 }
@@ -0,0 +1,266 @@
+// Package skins looks up the skins of Minecraft players.
+package skins
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// list of errors returned when looking up skins
+var (
+	ErrNotFound      = fmt.Errorf("player not found")
+	ErrInvalidPlayer = fmt.Errorf("invalid player")
+	ErrInvalidSkin   = fmt.Errorf("invalid skin")
+	ErrUnavailable   = fmt.Errorf("skin service unavailable")
+)
+
+// limits of lookups
+const (
+	maxResponseSize = 1 << 20
+	maxCachedSkins  = 1000
+)
+
+// patterns of player names and UUIDs, the latter with or without dashes
+var (
+	namePattern = regexp.MustCompile(`^[a-zA-Z0-9_]{1,16}$`)
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+)
+
+// ValidName reports whether the given text is a valid name of a player.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Options configures the services skins are looked up at.
+type Options struct {
+	// ProfileURL is the base URL returning the profile of a player by name, e.g. "https://api.mojang.com/users/profiles/minecraft/".
+	ProfileURL string
+
+	// SessionURL is the base URL returning the textures of a player by UUID, e.g. "https://sessionserver.mojang.com/session/minecraft/profile/".
+	SessionURL string
+
+	// CacheTTL is how long skins and unknown players are remembered.
+	CacheTTL time.Duration
+
+	// Timeout limits the duration of a whole lookup, including all requests.
+	Timeout time.Duration
+}
+
+// A Skin is the texture of a player.
+type Skin struct {
+	Image image.Image
+
+	// Slim is set for skins with arms that are 3 instead of 4 pixels wide.
+	Slim bool
+}
+
+// A Service looks up skins of players by their name or UUID and caches them.
+// It is safe for concurrent use.
+type Service struct {
+	options Options
+	client  *http.Client
+
+	lock  sync.Mutex
+	cache map[string]cachedSkin
+}
+
+// cachedSkin is a skin, or the error that it was not found, remembered until it expires.
+type cachedSkin struct {
+	skin    Skin
+	err     error
+	expires time.Time
+}
+
+// NewService returns a service looking up skins using the given options.
+func NewService(options Options) *Service {
+	return &Service{
+		options: options,
+		client:  &http.Client{Timeout: options.Timeout},
+		cache:   make(map[string]cachedSkin),
+	}
+}
+
+// Decode decodes a skin from the given PNG image, which must be 64x64 or 64x32 pixels.
+// The size is checked before decoding, so small files declaring huge images are rejected early.
+func Decode(data []byte) (image.Image, error) {
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSkin, err)
+	}
+	if config.Width != 64 || (config.Height != 64 && config.Height != 32) {
+		return nil, fmt.Errorf("%w: must be 64x64 or 64x32 pixels, not %dx%d", ErrInvalidSkin, config.Width, config.Height)
+	}
+
+	skin, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSkin, err)
+	}
+	return skin, nil
+}
+
+// Skin returns the skin of the player with the given name or UUID.
+// It returns ErrNotFound if the player does not exist or has no skin, and ErrUnavailable if the services cannot be reached in time.
+func (service *Service) Skin(ctx context.Context, player string) (Skin, error) {
+	if !namePattern.MatchString(player) && !uuidPattern.MatchString(player) {
+		return Skin{}, fmt.Errorf("%w: %q is neither name nor uuid", ErrInvalidPlayer, player)
+	}
+	key := strings.ToLower(strings.ReplaceAll(player, "-", ""))
+
+	service.lock.Lock()
+	cached, exists := service.cache[key]
+	service.lock.Unlock()
+	if exists && time.Now().Before(cached.expires) {
+		return cached.skin, cached.err
+	}
+
+	if service.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, service.options.Timeout)
+		defer cancel()
+	}
+	skin, err := service.lookup(ctx, key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Skin{}, err
+	}
+	service.remember(key, cachedSkin{skin: skin, err: err, expires: time.Now().Add(service.options.CacheTTL)})
+	return skin, err
+}
+
+// lookup fetches the skin of the player with the given name or UUID without dashes from the services.
+func (service *Service) lookup(ctx context.Context, player string) (Skin, error) {
+	id := player
+	if !uuidPattern.MatchString(player) {
+		var profile struct {
+			ID string `json:"id"`
+		}
+		if err := service.getJSON(ctx, service.options.ProfileURL+url.PathEscape(player), &profile); err != nil {
+			return Skin{}, err
+		}
+		id = profile.ID
+	}
+
+	var session struct {
+		Properties []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"properties"`
+	}
+	if err := service.getJSON(ctx, service.options.SessionURL+url.PathEscape(id), &session); err != nil {
+		return Skin{}, err
+	}
+
+	// the textures are given as base64-encoded JSON
+	var textures struct {
+		Textures struct {
+			Skin *struct {
+				URL      string `json:"url"`
+				Metadata struct {
+					Model string `json:"model"`
+				} `json:"metadata"`
+			} `json:"SKIN"`
+		} `json:"textures"`
+	}
+	for _, property := range session.Properties {
+		if property.Name != "textures" {
+			continue
+		}
+		content, err := base64.StdEncoding.DecodeString(property.Value)
+		if err != nil {
+			return Skin{}, fmt.Errorf("%w: decoding textures: %w", ErrUnavailable, err)
+		}
+		if err := json.Unmarshal(content, &textures); err != nil {
+			return Skin{}, fmt.Errorf("%w: decoding textures: %w", ErrUnavailable, err)
+		}
+	}
+	if textures.Textures.Skin == nil {
+		return Skin{}, fmt.Errorf("%w: %s has no skin", ErrNotFound, player)
+	}
+
+	content, err := service.get(ctx, textures.Textures.Skin.URL)
+	if err != nil {
+		return Skin{}, err
+	}
+	texture, err := Decode(content)
+	if err != nil {
+		return Skin{}, err
+	}
+	return Skin{Image: texture, Slim: textures.Textures.Skin.Metadata.Model == "slim"}, nil
+}
+
+// getJSON decodes the JSON returned by the given URL into v.
+func (service *Service) getJSON(ctx context.Context, rawURL string, v any) error {
+	content, err := service.get(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("%w: decoding %s: %w", ErrUnavailable, rawURL, err)
+	}
+	return nil
+}
+
+// get returns the body returned by the given URL.
+// Missing content is reported as ErrNotFound, all other failures as ErrUnavailable.
+func (service *Service) get(ctx context.Context, rawURL string) ([]byte, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("%w: invalid url %q", ErrUnavailable, rawURL)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	response, err := service.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusNoContent:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, rawURL)
+	case response.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: %s returned %s", ErrUnavailable, rawURL, response.Status)
+	}
+	content, err := io.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return content, nil
+}
+
+// remember caches the given lookup, dropping expired entries if the cache is full.
+func (service *Service) remember(key string, entry cachedSkin) {
+	service.lock.Lock()
+	defer service.lock.Unlock()
+
+	if len(service.cache) >= maxCachedSkins {
+		now := time.Now()
+		for cachedKey, cached := range service.cache {
+			if !now.Before(cached.expires) {
+				delete(service.cache, cachedKey)
+			}
+		}
+	}
+	// still full of fresh entries, so forget an arbitrary one
+	for cachedKey := range service.cache {
+		if len(service.cache) < maxCachedSkins {
+			break
+		}
+		delete(service.cache, cachedKey)
+	}
+	service.cache[key] = entry
+}
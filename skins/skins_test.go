@@ -0,0 +1,51 @@
+package skins
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func encode(t *testing.T, width int, height int) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, image.NewNRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"modern", encode(t, 64, 64), false},
+		{"legacy", encode(t, 64, 32), false},
+		{"too wide", encode(t, 4096, 64), true},
+		{"too high", encode(t, 64, 4096), true},
+		{"high definition", encode(t, 128, 128), true},
+		{"no png", []byte("not an image"), true},
+		{"empty", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			skin, err := Decode(test.data)
+			if test.wantErr {
+				if !errors.Is(err, ErrInvalidSkin) {
+					t.Errorf("Decode() error = %v, want %v", err, ErrInvalidSkin)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got := skin.Bounds().Size(); got.X != 64 {
+				t.Errorf("Decode() size = %v, want a width of 64", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/menzerath/mcgen/metrics"
+)
+
+// list of features an API key may be restricted to
+const (
+	FeatureAchievement = "achievement"
+	FeatureCollage     = "collage"
+	FeatureScreenshot  = "screenshot"
+	FeatureLinks       = "links"
+	FeatureImmutable   = "immutable"
+	FeatureHeads       = "heads"
+	FeaturePlayers     = "players"
+	FeatureBanners     = "banners"
+	FeatureRecipes     = "recipes"
+	FeatureTooltips    = "tooltips"
+	FeatureChat        = "chat"
+	FeatureSignboards  = "signboards"
+)
+
+// features contains all known features.
+var features = []string{FeatureAchievement, FeatureCollage, FeatureScreenshot, FeatureLinks, FeatureImmutable, FeatureHeads, FeaturePlayers, FeatureBanners, FeatureRecipes, FeatureTooltips, FeatureChat, FeatureSignboards}
+
+// the header and query parameter used to send an API key
+const (
+	apiKeyHeader    = "X-API-Key"
+	apiKeyParameter = "api_key"
+)
+
+// An APIKey grants access to the API with its own limits.
+type APIKey struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+
+	// RateLimit replaces the default rate limit; a zero limit allows unlimited requests.
+	RateLimit RateLimit `json:"rate_limit"`
+
+	// DailyQuota limits the number of requests per UTC day; zero allows unlimited requests.
+	DailyQuota int64 `json:"daily_quota"`
+
+	// Features restricts the key to the given features; an empty list allows all features.
+	Features []string `json:"features"`
+}
+
+// allows reports whether the key may use the given feature.
+func (key APIKey) allows(feature string) bool {
+	return len(key.Features) == 0 || slices.Contains(key.Features, feature)
+}
+
+// APIKeys configures authentication by API keys.
+type APIKeys struct {
+	Keys []APIKey
+
+	// Anonymous allows requests without an API key.
+	Anonymous bool
+
+	// AdminToken grants access to the usage of all API keys.
+	AdminToken string
+}
+
+// LoadAPIKeys reads a JSON list of API keys from the given file.
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading api keys: %w", err)
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return nil, fmt.Errorf("decoding api keys: %w", err)
+	}
+	if err := ValidateAPIKeys(keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ValidateAPIKeys checks that all keys are complete, unique and only use known features.
+func ValidateAPIKeys(keys []APIKey) error {
+	seenKeys := make(map[string]bool)
+	seenNames := make(map[string]bool)
+	for i, key := range keys {
+		if key.Key == "" || key.Name == "" {
+			return fmt.Errorf("api key %d: key and name must not be empty", i)
+		}
+		if seenKeys[key.Key] || seenNames[key.Name] {
+			return fmt.Errorf("api key %s: duplicate key or name", key.Name)
+		}
+		seenKeys[key.Key] = true
+		seenNames[key.Name] = true
+
+		if key.RateLimit.Rate < 0 || key.RateLimit.Burst < 0 || key.DailyQuota < 0 {
+			return fmt.Errorf("api key %s: limits must not be negative", key.Name)
+		}
+		for _, feature := range key.Features {
+			if !slices.Contains(features, feature) {
+				return fmt.Errorf("api key %s: unknown feature %q", key.Name, feature)
+			}
+		}
+	}
+	return nil
+}
+
+// APIKeyUsage is the response body for the usage of a single API key.
+type APIKeyUsage struct {
+	Name          string `json:"name"`
+	Day           string `json:"day"`
+	RequestsToday int64  `json:"requests_today"`
+	RequestsTotal int64  `json:"requests_total"`
+	Rejected      int64  `json:"rejected"`
+	DailyQuota    int64  `json:"daily_quota"`
+}
+
+// apiKeyState holds an API key together with its usage.
+type apiKeyState struct {
+	key   APIKey
+	usage APIKeyUsage
+}
+
+// apiKeyring authenticates requests and accounts for the usage of each API key.
+type apiKeyring struct {
+	config APIKeys
+	keys   map[[sha256.Size]byte]*apiKeyState
+	lock   sync.Mutex
+}
+
+// newAPIKeyring returns a keyring containing the given keys.
+func newAPIKeyring(config APIKeys) *apiKeyring {
+	keyring := &apiKeyring{
+		config: config,
+		keys:   make(map[[sha256.Size]byte]*apiKeyState),
+	}
+	for _, key := range config.Keys {
+		keyring.keys[sha256.Sum256([]byte(key.Key))] = &apiKeyState{
+			key:   key,
+			usage: APIKeyUsage{Name: key.Name, DailyQuota: key.DailyQuota},
+		}
+	}
+	return keyring
+}
+
+// contextKey is the type of all keys used to store values in a request's context.
+type contextKey int
+
+const apiKeyContextKey contextKey = iota
+
+// apiKeyFromContext returns the API key the request was authenticated with.
+func apiKeyFromContext(ctx context.Context) (APIKey, bool) {
+	state, ok := ctx.Value(apiKeyContextKey).(*apiKeyState)
+	if !ok {
+		return APIKey{}, false
+	}
+	return state.key, true
+}
+
+// authenticate looks up the API key sent with the request and stores it in the request's context.
+// Requests with an unknown key and, unless anonymous access is allowed, requests without a key are rejected.
+func (keyring *apiKeyring) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := r.Header.Get(apiKeyHeader)
+		if secret == "" {
+			secret = r.URL.Query().Get(apiKeyParameter)
+		}
+
+		if secret == "" {
+			if !keyring.config.Anonymous {
+				writeJSON(w, http.StatusUnauthorized, ErrorResponse{
+					Error:   "missing api key",
+					Message: "this instance requires an api key",
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		state, exists := keyring.keys[sha256.Sum256([]byte(secret))]
+		if !exists {
+			writeJSON(w, http.StatusUnauthorized, ErrorResponse{
+				Error:   "unknown api key",
+				Message: "invalid api key",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey, state)))
+	})
+}
+
+// feature returns a middleware rejecting requests whose API key does not allow the given feature or has exhausted its daily quota.
+// Accepted requests count towards the key's usage.
+func (keyring *apiKeyring) feature(feature string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state, ok := r.Context().Value(apiKeyContextKey).(*apiKeyState)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !state.key.allows(feature) {
+				keyring.reject(state)
+				metrics.APIKeyRequests.WithLabelValues(state.key.Name, "forbidden").Inc()
+				writeJSON(w, http.StatusForbidden, ErrorResponse{
+					Error:   fmt.Sprintf("feature %q not allowed", feature),
+					Message: "this api key may not use this feature",
+				})
+				return
+			}
+
+			now := time.Now().UTC()
+			if !keyring.consume(state, now) {
+				metrics.APIKeyRequests.WithLabelValues(state.key.Name, "quota_exceeded").Inc()
+				tomorrow := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+				w.Header().Set("Retry-After", strconv.Itoa(int(tomorrow.Sub(now).Seconds())+1))
+				writeJSON(w, http.StatusTooManyRequests, ErrorResponse{
+					Error:   fmt.Sprintf("daily quota of %d requests exceeded", state.key.DailyQuota),
+					Message: "too many requests",
+				})
+				return
+			}
+			metrics.APIKeyRequests.WithLabelValues(state.key.Name, "accepted").Inc()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// consume counts a request towards the key's usage and reports whether it is within its daily quota.
+func (keyring *apiKeyring) consume(state *apiKeyState, now time.Time) bool {
+	keyring.lock.Lock()
+	defer keyring.lock.Unlock()
+
+	if day := now.Format(time.DateOnly); state.usage.Day != day {
+		state.usage.Day = day
+		state.usage.RequestsToday = 0
+	}
+	if state.key.DailyQuota > 0 && state.usage.RequestsToday >= state.key.DailyQuota {
+		state.usage.Rejected++
+		return false
+	}
+
+	state.usage.RequestsToday++
+	state.usage.RequestsTotal++
+	return true
+}
+
+// reject counts a rejected request towards the key's usage.
+func (keyring *apiKeyring) reject(state *apiKeyState) {
+	keyring.lock.Lock()
+	defer keyring.lock.Unlock()
+
+	state.usage.Rejected++
+}
+
+// admin only passes requests sending the admin token.
+func (keyring *apiKeyring) admin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(keyring.config.AdminToken)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, ErrorResponse{
+				Error:   "missing or invalid token",
+				Message: "this route requires a valid admin token",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// usageGet returns the usage of all API keys.
+func (keyring *apiKeyring) usageGet(w http.ResponseWriter, r *http.Request) {
+	keyring.lock.Lock()
+	usage := make([]APIKeyUsage, 0, len(keyring.keys))
+	for _, state := range keyring.keys {
+		usage = append(usage, state.usage)
+	}
+	keyring.lock.Unlock()
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Name < usage[j].Name
+	})
+	writeJSON(w, http.StatusOK, usage)
+}
@@ -0,0 +1,152 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/freetype/truetype"
+	"github.com/menzerath/mcgen/assets"
+	"github.com/menzerath/mcgen/generator"
+)
+
+// maxAssetUploadSize limits the size of uploaded assets in bytes.
+const maxAssetUploadSize = 8 << 20
+
+// assetsGet lists all assets of the generator.
+func (web WebAPI) assetsGet(w http.ResponseWriter, r *http.Request) {
+	response := make(AssetsResponse, len(generator.AssetKinds))
+	for _, kind := range generator.AssetKinds {
+		response[kind] = web.Generator.Assets.Names(kind)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// assetPut adds the asset given as PNG image, TrueType font or JSON block in the request body.
+// Existing assets are only replaced if the replace parameter is set to true.
+func (web WebAPI) assetPut(w http.ResponseWriter, r *http.Request) {
+	kind := generator.AssetKind(chi.URLParam(r, "kind"))
+	name := chi.URLParam(r, "name")
+	if !slices.Contains(generator.AssetKinds, kind) {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   "unknown asset kind",
+			Message: "assets are either backgrounds, frames, icons, fonts or blocks",
+		})
+		return
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAssetUploadSize))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	replace := r.URL.Query().Get("replace") == "true"
+	registry := web.Generator.Assets
+	switch kind {
+	case generator.KindBlock:
+		var block assets.Block
+		if decodeErr := json.Unmarshal(content, &block); decodeErr != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   decodeErr.Error(),
+				Message: "invalid block",
+			})
+			return
+		}
+		err = registry.AddBlock(name, block, replace)
+	case generator.KindFont:
+		parsedFont, parseErr := truetype.Parse(content)
+		if parseErr != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   parseErr.Error(),
+				Message: "invalid font",
+			})
+			return
+		}
+		err = registry.AddFont(name, parsedFont, replace)
+	default:
+		// check the dimensions before decoding, so small files cannot decode into huge images
+		config, _, decodeErr := image.DecodeConfig(bytes.NewReader(content))
+		if decodeErr != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   decodeErr.Error(),
+				Message: "invalid image",
+			})
+			return
+		}
+		size := image.Pt(config.Width, config.Height)
+		sizeErr := assets.CheckBackgroundSize(size)
+		if kind == generator.KindIcon {
+			sizeErr = assets.CheckIconSize(size)
+		}
+		if sizeErr != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   sizeErr.Error(),
+				Message: "invalid asset",
+			})
+			return
+		}
+
+		img, _, decodeErr := image.Decode(bytes.NewReader(content))
+		if decodeErr != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   decodeErr.Error(),
+				Message: "invalid image",
+			})
+			return
+		}
+		switch kind {
+		case generator.KindBackground:
+			err = registry.AddBackground(name, img, replace)
+		case generator.KindFrame:
+			err = registry.AddFrame(name, img, replace)
+		case generator.KindIcon:
+			err = registry.AddIcon(name, img, replace)
+		}
+	}
+
+	switch {
+	case errors.Is(err, generator.ErrAssetExists):
+		writeJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   err.Error(),
+			Message: "asset already exists, set replace=true to replace it",
+		})
+	case err != nil:
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid asset",
+		})
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// assetDelete removes an asset.
+func (web WebAPI) assetDelete(w http.ResponseWriter, r *http.Request) {
+	kind := generator.AssetKind(chi.URLParam(r, "kind"))
+	name := chi.URLParam(r, "name")
+	removed, err := web.Generator.Assets.Remove(kind, name)
+	if err != nil {
+		writeJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   err.Error(),
+			Message: "default assets are used by all renders",
+		})
+		return
+	}
+	if !removed {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   "unknown asset",
+			Message: "there is no such asset",
+		})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
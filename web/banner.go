@@ -0,0 +1,99 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/metrics"
+)
+
+// bannerGet renders the banner given by the `base` colour and pairs of `pattern` and `color` parameters, or as item by `give`.
+func (web WebAPI) bannerGet(w http.ResponseWriter, r *http.Request) {
+	request, err := bannerQueryRequest(r.URL.Query())
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	web.generateAndReturnBanner(w, r, request)
+}
+
+func (web WebAPI) bannerPost(w http.ResponseWriter, r *http.Request) {
+	var request BannerRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	web.generateAndReturnBanner(w, r, request)
+}
+
+func (web WebAPI) generateAndReturnBanner(w http.ResponseWriter, r *http.Request, request BannerRequest) {
+	banner, err := request.banner()
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	timeStart := time.Now()
+	image, err := web.Generator.GenerateBanner(r.Context(), banner, request.Scale)
+	if err != nil {
+		writeGeneratorError(w, r, err, "could not generate banner")
+		return
+	}
+	metrics.RenderRuntime.WithLabelValues("banner").Observe(time.Since(timeStart).Seconds())
+	slog.Info(
+		"generated banner",
+		"base", banner.Base,
+		"patterns", len(banner.Patterns),
+		"runtime", time.Since(timeStart).Seconds(),
+	)
+
+	writeImage(w, request.Output, "banner.png", "image/png", image)
+}
+
+// bannerQueryRequest parses the query parameters of the banner API.
+// Patterns and colours are paired by their position.
+func bannerQueryRequest(query url.Values) (BannerRequest, error) {
+	request := BannerRequest{
+		Base:   query.Get("base"),
+		Give:   query.Get("give"),
+		Output: AchievementOutputType(query.Get("output")),
+	}
+
+	patterns, colors := query["pattern"], query["color"]
+	if len(patterns) != len(colors) {
+		return BannerRequest{}, requestError{message: "invalid banner", err: errUnpairedPatterns}
+	}
+	for i := range patterns {
+		request.Patterns = append(request.Patterns, generator.BannerPattern{Pattern: patterns[i], Color: colors[i]})
+	}
+
+	if value := query.Get("scale"); value != "" {
+		var err error
+		if request.Scale, err = strconv.Atoi(value); err != nil {
+			return BannerRequest{}, requestError{message: "invalid scale", err: err}
+		}
+	}
+	return request, nil
+}
+
+// banner converts the request into the banner to render; a banner given as item replaces all other fields.
+func (request BannerRequest) banner() (generator.Banner, error) {
+	if request.Give == "" {
+		return generator.Banner{Base: request.Base, Patterns: request.Patterns}, nil
+	}
+	banner, err := generator.ParseBannerItem(request.Give)
+	if err != nil {
+		return generator.Banner{}, requestError{message: "invalid banner", err: err}
+	}
+	return banner, nil
+}
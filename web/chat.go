@@ -0,0 +1,90 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/metrics"
+	"github.com/menzerath/mcgen/skins"
+)
+
+func (web WebAPI) chatPost(w http.ResponseWriter, r *http.Request) {
+	var request ChatRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+	for _, line := range request.Lines {
+		if line.Player != "" && !skins.ValidName(line.Player) {
+			writeRequestError(w, requestError{message: "invalid player", err: fmt.Errorf("%w: %s", skins.ErrInvalidPlayer, line.Player)})
+			return
+		}
+	}
+	if err := web.filterChat(&request); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	timeStart := time.Now()
+	image, err := web.Generator.GenerateChat(r.Context(), request.Lines, request.Width)
+	if err != nil {
+		writeGeneratorError(w, r, err, "could not generate chat")
+		return
+	}
+	metrics.RenderRuntime.WithLabelValues("chat").Observe(time.Since(timeStart).Seconds())
+	slog.Info(
+		"generated chat",
+		"lines", len(request.Lines),
+		"width", request.Width,
+		"runtime", time.Since(timeStart).Seconds(),
+	)
+
+	writeImage(w, request.Output, "chat.png", "image/png", image)
+}
+
+// filterChat applies the content filter to the players and messages of the request, as they are shown.
+// Masked texts replace the original ones and lose their formatting; as text components cannot be masked, they are rejected instead.
+func (web WebAPI) filterChat(request *ChatRequest) error {
+	if web.Filter == nil {
+		return nil
+	}
+
+	var err error
+	for i := range request.Lines {
+		line := &request.Lines[i]
+		if line.Player, err = web.Filter.Apply(line.Player); err != nil {
+			return requestError{message: "player rejected by content filter", err: err}
+		}
+		if line.Text, err = web.filterFormatted(line.Text); err != nil {
+			return requestError{message: "message rejected by content filter", err: err}
+		}
+		if len(line.Component) == 0 {
+			continue
+		}
+
+		spans, err := generator.ParseTextComponent(line.Component, generator.TextStyle{})
+		if err != nil {
+			return requestError{message: "invalid text component", err: err}
+		}
+		var text strings.Builder
+		for _, span := range spans {
+			text.WriteString(span.Text)
+		}
+		masked, err := web.Filter.Apply(text.String())
+		if err == nil && masked != text.String() {
+			err = errComponentFiltered
+		}
+		if err != nil {
+			return requestError{message: "message rejected by content filter", err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/metrics"
+)
+
+func (web WebAPI) collageGet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// achievements are given as repeated parameters and matched by their position
+	backgrounds := query["background"]
+	titles := query["title"]
+	texts := query["text"]
+	if len(titles) != len(backgrounds) || len(texts) != len(backgrounds) {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "mismatching number of backgrounds, titles and texts",
+			Message: "invalid achievements",
+		})
+		return
+	}
+
+	request := CollageRequest{
+		Output: AchievementOutputType(query.Get("output")),
+	}
+	for i := range backgrounds {
+		request.Achievements = append(request.Achievements, AchievementRequest{
+			Background: backgrounds[i],
+			Title:      titles[i],
+			Text:       texts[i],
+		})
+	}
+
+	for name, target := range map[string]*int{"columns": &request.Columns, "padding": &request.Padding} {
+		if query.Get(name) == "" {
+			continue
+		}
+		value, err := strconv.Atoi(query.Get(name))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   err.Error(),
+				Message: "invalid " + name,
+			})
+			return
+		}
+		*target = value
+	}
+
+	web.generateAndReturnCollage(w, r, request)
+}
+
+func (web WebAPI) collagePost(w http.ResponseWriter, r *http.Request) {
+	var request CollageRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	web.generateAndReturnCollage(w, r, request)
+}
+
+func (web WebAPI) generateAndReturnCollage(w http.ResponseWriter, r *http.Request, request CollageRequest) {
+	// checked before converting the achievements, which may look up the skins of players
+	if len(request.Achievements) > generator.MaxCollageAchievements {
+		writeGeneratorError(w, r, generator.ErrTooManyAchievements, "could not generate collage")
+		return
+	}
+
+	achievements := make([]generator.Achievement, 0, len(request.Achievements))
+	for _, achievement := range request.Achievements {
+		if err := web.filterAchievement(&achievement); err != nil {
+			writeRequestError(w, err)
+			return
+		}
+		converted, err := web.achievement(r.Context(), achievement)
+		if err != nil {
+			writeAchievementError(w, r, err)
+			return
+		}
+		achievements = append(achievements, converted)
+	}
+
+	timeStart := time.Now()
+	collage, err := web.Generator.GenerateCollage(r.Context(), achievements, generator.CollageOptions{
+		Columns: request.Columns,
+		Padding: request.Padding,
+	})
+	if err != nil {
+		writeGeneratorError(w, r, err, "could not generate collage")
+		return
+	}
+	metrics.AchievementGenerationRuntime.Observe(time.Since(timeStart).Seconds())
+	slog.Info(
+		"generated collage",
+		"achievements", len(achievements),
+		"columns", request.Columns,
+		"runtime", time.Since(timeStart).Seconds(),
+	)
+
+	writeImage(w, request.Output, "achievements.png", "image/png", collage)
+}
@@ -1,11 +1,34 @@
 package web
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/menzerath/mcgen/generator"
+)
+
 // AchievementRequest is the request body for the achievement endpoint.
 type AchievementRequest struct {
 	Background string `json:"background"`
 	Title      string `json:"title"`
 	Text       string `json:"text"`
 
+	// Enchanted adds the enchantment glint to the icon, which is only visible moving if the achievement is Animated.
+	Enchanted bool     `json:"enchanted,omitempty"`
+	Tints     []string `json:"tints,omitempty"`
+	Animated  bool     `json:"animated,omitempty"`
+
+	// Count and Durability decorate the icon like an item stack; see generator.IconModifiers.
+	Count      int  `json:"count,omitempty"`
+	Durability *int `json:"durability,omitempty"`
+
+	// Player replaces the background by the head of the player with this name or UUID, shown in the Head style.
+	Player string              `json:"player,omitempty"`
+	Head   generator.HeadStyle `json:"head,omitempty"`
+
+	// Banner replaces the background by a banner given as item, e.g. `red_banner[banner_patterns=[...]]`.
+	Banner string `json:"banner,omitempty"`
+
 	Output AchievementOutputType `json:"output"`
 }
 
@@ -23,3 +46,105 @@ var (
 	AchievementOutputTypeDefault  AchievementOutputType = ""
 	AchievementOutputTypeDownload AchievementOutputType = "download"
 )
+
+// CollageRequest is the request body for the collage endpoint.
+type CollageRequest struct {
+	Achievements []AchievementRequest `json:"achievements"`
+	Columns      int                  `json:"columns"`
+	Padding      int                  `json:"padding"`
+
+	Output AchievementOutputType `json:"output"`
+}
+
+// BannerRequest is the request body for the banner endpoint.
+// The banner is given either by its Base colour and Patterns, or as item by Give; see generator.ParseBannerItem.
+type BannerRequest struct {
+	Base     string                    `json:"base"`
+	Patterns []generator.BannerPattern `json:"patterns"`
+	Give     string                    `json:"give,omitempty"`
+	Scale    int                       `json:"scale,omitempty"`
+
+	Output AchievementOutputType `json:"output"`
+}
+
+// RecipeRequest is the request body for the recipe endpoint.
+// The recipe is given either by its Type, Grid, Fuel, Result and Count, or as the game's recipe file in Recipe; see generator.ParseRecipe.
+type RecipeRequest struct {
+	Type   generator.RecipeType `json:"type"`
+	Grid   [][]string           `json:"grid"`
+	Fuel   string               `json:"fuel,omitempty"`
+	Result string               `json:"result"`
+	Count  int                  `json:"count,omitempty"`
+	Recipe json.RawMessage      `json:"recipe,omitempty"`
+
+	Output AchievementOutputType `json:"output"`
+}
+
+// TooltipRequest is the request body for the tooltip endpoint; all texts may contain the game's formatting codes.
+type TooltipRequest struct {
+	Name         string                        `json:"name"`
+	Rarity       generator.Rarity              `json:"rarity,omitempty"`
+	Enchantments []generator.Enchantment       `json:"enchantments,omitempty"`
+	Lore         []string                      `json:"lore,omitempty"`
+	Attributes   []generator.AttributeModifier `json:"attributes,omitempty"`
+
+	Output AchievementOutputType `json:"output"`
+}
+
+// ChatRequest is the request body for the chat endpoint.
+// The chat's width is given in pixels of the game's GUI; see generator.GenerateChat.
+type ChatRequest struct {
+	Lines []generator.ChatLine `json:"lines"`
+	Width int                  `json:"width,omitempty"`
+
+	Output AchievementOutputType `json:"output"`
+}
+
+// SignboardRequest is the request body for the sign endpoint; the lines may contain the game's formatting codes.
+// The text is dyed by the ID of a dye Color, and Glowing text looks like after applying a glow ink sac; see generator.Sign.
+type SignboardRequest struct {
+	Wood    string   `json:"wood"`
+	Hanging bool     `json:"hanging,omitempty"`
+	Lines   []string `json:"lines"`
+	Color   string   `json:"color,omitempty"`
+	Glowing bool     `json:"glowing,omitempty"`
+
+	Output AchievementOutputType `json:"output"`
+}
+
+// LinkRequest is the request body for the link endpoint.
+// It contains the achievement to store and optionally a custom slug and an expiry in seconds.
+type LinkRequest struct {
+	AchievementRequest
+
+	Slug      string `json:"slug"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+// LinkResponse is the response body for the link endpoint.
+type LinkResponse struct {
+	ID        string     `json:"id"`
+	Path      string     `json:"path"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ImmutableResponse is the response body for the immutable URL endpoint.
+type ImmutableResponse struct {
+	Path string `json:"path"`
+}
+
+// SignRequest is the request body for the signing endpoint.
+type SignRequest struct {
+	URL       string `json:"url"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+// SignResponse is the response body for the signing endpoint.
+type SignResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AssetsResponse is the response body for the assets endpoint.
+// It lists the names of all assets by their kind.
+type AssetsResponse map[generator.AssetKind][]string
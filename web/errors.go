@@ -0,0 +1,150 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/skins"
+)
+
+// overloadedRetryAfter is the number of seconds clients should wait before retrying if the generator is overloaded.
+const overloadedRetryAfter = "1"
+
+// A requestError describes why a request could not be parsed.
+type requestError struct {
+	message string
+	err     error
+}
+
+func (e requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e requestError) Unwrap() error {
+	return e.err
+}
+
+// writeRequestError writes the given error as a bad request.
+func writeRequestError(w http.ResponseWriter, err error) {
+	message := "invalid request"
+	if requestErr, ok := err.(requestError); ok {
+		message = requestErr.message
+	}
+
+	writeJSON(w, http.StatusBadRequest, ErrorResponse{
+		Error:   err.Error(),
+		Message: message,
+	})
+}
+
+// writeAchievementError writes an error returned while converting a request into an achievement.
+// Players that cannot be found and failures of the skin service are distinguished from invalid requests.
+func writeAchievementError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, skins.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   err.Error(),
+			Message: "unknown player",
+		})
+	case errors.Is(err, skins.ErrInvalidPlayer):
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid player",
+		})
+	case errors.Is(err, skins.ErrInvalidSkin):
+		writeJSON(w, http.StatusBadGateway, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid skin",
+		})
+	case errors.Is(err, skins.ErrUnavailable) || errors.Is(err, context.DeadlineExceeded):
+		slog.Warn("looking up skin", "path", r.URL.Path, "error", err)
+		writeJSON(w, http.StatusBadGateway, ErrorResponse{
+			Error:   err.Error(),
+			Message: "skin service unavailable",
+		})
+	default:
+		writeRequestError(w, err)
+	}
+}
+
+// generatorErrorMessages maps errors caused by invalid requests to the message returned to the client.
+var generatorErrorMessages = map[error]string{
+	generator.ErrUnknownBackground:   "unknown background",
+	generator.ErrNoAchievements:      "invalid collage",
+	generator.ErrTooManyAchievements: "invalid collage",
+	generator.ErrInvalidColumns:      "invalid collage",
+	generator.ErrInvalidPadding:      "invalid collage",
+	generator.ErrInvalidScreenshot:   "invalid screenshot",
+	generator.ErrInvalidGUIScale:     "invalid gui scale",
+	generator.ErrNotTintable:         "invalid tint",
+	generator.ErrTooManyTints:        "invalid tint",
+	generator.ErrInvalidCount:        "invalid count",
+	generator.ErrInvalidDurability:   "invalid durability",
+	generator.ErrInvalidSkin:         "invalid skin",
+	generator.ErrInvalidHeadStyle:    "invalid head",
+	generator.ErrInvalidPlayerModel:  "invalid model",
+	generator.ErrInvalidPlayerView:   "invalid view",
+	generator.ErrInvalidPlayerPose:   "invalid pose",
+	generator.ErrInvalidPlayerScale:  "invalid scale",
+	generator.ErrUnknownPattern:      "invalid banner",
+	generator.ErrUnknownDyeColor:     "invalid banner",
+	generator.ErrTooManyPatterns:     "invalid banner",
+	generator.ErrInvalidBannerScale:  "invalid scale",
+	generator.ErrUnknownItem:         "unknown item",
+	generator.ErrInvalidRecipeType:   "invalid recipe",
+	generator.ErrInvalidRecipeGrid:   "invalid recipe",
+	generator.ErrMissingResult:       "invalid recipe",
+	generator.ErrInvalidRarity:       "invalid tooltip",
+	generator.ErrInvalidSlot:         "invalid tooltip",
+	generator.ErrInvalidOperation:    "invalid tooltip",
+	generator.ErrTooManyLines:        "invalid tooltip",
+	generator.ErrLineTooLong:         "invalid tooltip",
+	generator.ErrInvalidComponent:    "invalid text component",
+	generator.ErrInvalidChatType:     "invalid chat",
+	generator.ErrInvalidChatWidth:    "invalid chat",
+	generator.ErrMissingPlayer:       "invalid chat",
+	generator.ErrEmptyChat:           "invalid chat",
+	generator.ErrTooManyChatLines:    "invalid chat",
+	generator.ErrUnknownWoodType:     "invalid sign",
+	generator.ErrTooManySignLines:    "invalid sign",
+	generator.ErrSignLineTooWide:     "invalid sign",
+}
+
+// writeGeneratorError writes an error returned by the generator.
+// Errors caused by the request are returned as bad requests, all others are logged and returned as internal errors using the given message.
+func writeGeneratorError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	for target, targetMessage := range generatorErrorMessages {
+		if errors.Is(err, target) {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   err.Error(),
+				Message: targetMessage,
+			})
+			return
+		}
+	}
+
+	switch {
+	case errors.Is(err, generator.ErrOverloaded):
+		w.Header().Set("Retry-After", overloadedRetryAfter)
+		writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{
+			Error:   err.Error(),
+			Message: "server is overloaded",
+		})
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		// the client is gone, so nobody will read our response anyway
+		slog.Debug("request cancelled while generating", "path", r.URL.Path, "error", err)
+		writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{
+			Error:   err.Error(),
+			Message: "request cancelled",
+		})
+	default:
+		slog.Error("generating image", "path", r.URL.Path, "error", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   err.Error(),
+			Message: message,
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package web
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/skins"
+)
+
+// maxSkinUploadSize limits the size of uploaded skins in bytes.
+const maxSkinUploadSize = 1 << 20
+
+// headPost generates an achievement showing the head of an uploaded skin as icon.
+// It expects a multipart form with the skin in the "skin" field and the achievement in the regular fields.
+func (web WebAPI) headPost(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSkinUploadSize)
+	if err := r.ParseMultipartForm(maxSkinUploadSize); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	file, _, err := r.FormFile("skin")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "missing skin",
+		})
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid skin",
+		})
+		return
+	}
+	skin, err := skins.Decode(content)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid skin",
+		})
+		return
+	}
+
+	// the remaining fields are the same as the achievement API's query parameters, except for the player
+	request, err := achievementQueryRequest(r.Form)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	request.Player = ""
+	if err := web.filterAchievement(&request); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	achievement, err := request.achievement()
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	achievement.Head = &generator.Head{Skin: skin, Style: request.Head}
+
+	web.returnAchievement(w, r, request, achievement)
+}
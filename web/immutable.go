@@ -0,0 +1,205 @@
+package web
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/links"
+)
+
+// renderSpecVersion is the current version of the render spec format.
+// It must be increased whenever the meaning of an encoded spec changes.
+const renderSpecVersion = 1
+
+// list of errors returned when handling render specs
+var (
+	errInvalidRenderSpec            = errors.New("invalid render spec")
+	errUnsupportedRenderSpecVersion = errors.New("unsupported render spec version")
+	errUnsupportedRequestURL        = errors.New("unsupported request url")
+)
+
+// renderSpec is the compact serialisation of a render request used in immutable URLs.
+// Its fields are kept in a fixed order, so equal requests always result in the same URL.
+type renderSpec struct {
+	Version    int      `json:"v"`
+	Build      string   `json:"b,omitempty"`
+	Background string   `json:"bg"`
+	Title      string   `json:"t"`
+	Text       string   `json:"x"`
+	Enchanted  bool     `json:"e,omitempty"`
+	Tints      []string `json:"c,omitempty"`
+	Count      int      `json:"n,omitempty"`
+	Durability *int     `json:"d,omitempty"`
+	Player     string   `json:"p,omitempty"`
+	Head       string   `json:"h,omitempty"`
+	Banner     string   `json:"bn,omitempty"`
+}
+
+// ImmutableURL returns the canonical immutable path rendering the given request.
+// It does not include whether the image should be downloaded, as this does not change the image itself.
+// Animated achievements use the extension ".gif" instead of ".png".
+func (web WebAPI) ImmutableURL(request AchievementRequest) string {
+	content, _ := json.Marshal(renderSpec{
+		Version:    renderSpecVersion,
+		Build:      web.Build,
+		Background: request.Background,
+		Title:      request.Title,
+		Text:       request.Text,
+		Enchanted:  request.Enchanted,
+		Tints:      request.Tints,
+		Count:      request.Count,
+		Durability: request.Durability,
+		Player:     request.Player,
+		Head:       string(request.Head),
+		Banner:     request.Banner,
+	})
+
+	extension := "png"
+	if request.Animated {
+		extension = "gif"
+	}
+	return fmt.Sprintf("/r/%s.%s", base64.RawURLEncoding.EncodeToString(content), extension)
+}
+
+// decodeRenderSpec decodes an encoded render spec into the request it describes, which is animated for the extension "gif".
+func decodeRenderSpec(encoded string, extension string) (AchievementRequest, error) {
+	content, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return AchievementRequest{}, requestError{message: "invalid render spec", err: fmt.Errorf("%w: %w", errInvalidRenderSpec, err)}
+	}
+
+	var spec renderSpec
+	if err := json.Unmarshal(content, &spec); err != nil {
+		return AchievementRequest{}, requestError{message: "invalid render spec", err: fmt.Errorf("%w: %w", errInvalidRenderSpec, err)}
+	}
+	if spec.Version != renderSpecVersion {
+		return AchievementRequest{}, requestError{message: "invalid render spec", err: fmt.Errorf("%w: %d", errUnsupportedRenderSpecVersion, spec.Version)}
+	}
+
+	return AchievementRequest{
+		Background: spec.Background,
+		Title:      spec.Title,
+		Text:       spec.Text,
+		Enchanted:  spec.Enchanted,
+		Tints:      spec.Tints,
+		Count:      spec.Count,
+		Durability: spec.Durability,
+		Player:     spec.Player,
+		Head:       generator.HeadStyle(spec.Head),
+		Banner:     spec.Banner,
+		Animated:   extension == "gif",
+	}, nil
+}
+
+// ParseRequestURL parses any URL supported by the web API into the request it describes.
+// This includes the legacy APIs, the achievement API, short links and immutable URLs.
+func (web WebAPI) ParseRequestURL(rawURL string) (AchievementRequest, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return AchievementRequest{}, requestError{message: "invalid url", err: err}
+	}
+
+	path := parsed.EscapedPath()
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	switch {
+	case path == "/a.php":
+		return web.legacyQueryRequest(parsed.Query()), nil
+	case path == "/api/v1/achievement":
+		return achievementQueryRequest(parsed.Query())
+	case len(segments) == 4 && segments[0] == "a":
+		return web.legacyPathRequest(segments[1], segments[2], segments[3])
+	case len(segments) == 2 && segments[0] == "r" && (strings.HasSuffix(segments[1], ".png") || strings.HasSuffix(segments[1], ".gif")):
+		encoded, extension, _ := strings.Cut(segments[1], ".")
+		return decodeRenderSpec(encoded, extension)
+	case len(segments) == 2 && segments[0] == "s" && web.Links != nil:
+		return web.linkRequest(segments[1])
+	}
+
+	return AchievementRequest{}, requestError{message: "unsupported url", err: fmt.Errorf("%w: %s", errUnsupportedRequestURL, path)}
+}
+
+func (web WebAPI) renderGet(w http.ResponseWriter, r *http.Request) {
+	extension := chi.URLParam(r, "ext")
+	if extension != "png" && extension != "gif" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   fmt.Sprintf("unsupported extension %q", chi.URLParam(r, "ext")),
+			Message: "invalid render spec",
+		})
+		return
+	}
+
+	request, err := decodeRenderSpec(chi.URLParam(r, "spec"), extension)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	request.Output = AchievementOutputType(r.URL.Query().Get("output"))
+
+	// the same spec describes the same image until skins, mappings or assets change, so successful renders may be cached for a while
+	cached := cachingResponseWriter{ResponseWriter: w, cacheControl: fmt.Sprintf("public, max-age=%d", int(renderCacheMaxAge.Seconds()))}
+	web.generateAndReturnAchievement(cached, r, request)
+}
+
+// renderCacheMaxAge is how long immutable URLs may be cached.
+// Specs do not include the player's skin, mappings and assets, which may change, so it is bounded.
+const renderCacheMaxAge = 24 * time.Hour
+
+// cachingResponseWriter sets its Cache-Control header on successful responses only, so errors are not cached.
+type cachingResponseWriter struct {
+	http.ResponseWriter
+	cacheControl string
+}
+
+func (w cachingResponseWriter) WriteHeader(statusCode int) {
+	if statusCode == http.StatusOK {
+		w.Header().Set("Cache-Control", w.cacheControl)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// immutableGet returns the immutable URL for the request given as `url` parameter.
+// Without that parameter, the query itself is treated as a request to the achievement API.
+func (web WebAPI) immutableGet(w http.ResponseWriter, r *http.Request) {
+	request, err := achievementQueryRequest(r.URL.Query())
+	if rawURL := r.URL.Query().Get("url"); rawURL != "" {
+		request, err = web.ParseRequestURL(rawURL)
+	}
+	if err != nil {
+		if errors.Is(err, links.ErrNotFound) {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{
+				Error:   err.Error(),
+				Message: "unknown link",
+			})
+			return
+		}
+		writeRequestError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ImmutableResponse{
+		Path: web.ImmutableURL(request),
+	})
+}
+
+func (web WebAPI) immutablePost(w http.ResponseWriter, r *http.Request) {
+	var request AchievementRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ImmutableResponse{
+		Path: web.ImmutableURL(request),
+	})
+}
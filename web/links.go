@@ -0,0 +1,165 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/menzerath/mcgen/links"
+)
+
+// limits for short links
+const (
+	maxLinkRequestSize = 16 << 10
+	maxLinkIDAttempts  = 5
+	maxLinkLifetime    = 10 * 365 * 24 * time.Hour
+)
+
+func (web WebAPI) linkPost(w http.ResponseWriter, r *http.Request) {
+	var request LinkRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLinkRequestSize)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+	// compared in seconds, as larger values would overflow the duration
+	if request.ExpiresIn < 0 || request.ExpiresIn > int64(maxLinkLifetime.Seconds()) {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   fmt.Sprintf("expires_in must be between 0 and %d seconds", int64(maxLinkLifetime.Seconds())),
+			Message: "invalid expiry",
+		})
+		return
+	}
+
+	// make sure we only store achievements we are actually able to render
+	if err := web.filterAchievement(&request.AchievementRequest); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	achievement, err := web.achievement(r.Context(), request.AchievementRequest)
+	if err != nil {
+		writeAchievementError(w, r, err)
+		return
+	}
+	if _, err := web.Generator.GenerateAchievement(r.Context(), achievement); err != nil {
+		writeGeneratorError(w, r, err, "could not generate achievement")
+		return
+	}
+
+	// store the normalised request: whether to download the image is decided by whoever opens the link
+	stored, err := json.Marshal(AchievementRequest{
+		Background: request.Background,
+		Title:      request.Title,
+		Text:       request.Text,
+		Enchanted:  request.Enchanted,
+		Tints:      request.Tints,
+		Animated:   request.Animated,
+		Count:      request.Count,
+		Durability: request.Durability,
+		Player:     request.Player,
+		Head:       request.Head,
+		Banner:     request.Banner,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   err.Error(),
+			Message: "could not create link",
+		})
+		return
+	}
+
+	link := links.Link{
+		Request:   stored,
+		CreatedAt: time.Now().UTC(),
+	}
+	if request.ExpiresIn > 0 {
+		expiresAt := link.CreatedAt.Add(time.Duration(request.ExpiresIn) * time.Second)
+		link.ExpiresAt = &expiresAt
+	}
+
+	if request.Slug != "" {
+		if err := links.ValidateSlug(request.Slug); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   err.Error(),
+				Message: "invalid slug",
+			})
+			return
+		}
+		link.ID = request.Slug
+		err = web.Links.Create(link)
+	} else {
+		// retry a few times in the unlikely case of a collision
+		for attempt := 0; attempt < maxLinkIDAttempts; attempt++ {
+			link.ID = links.NewID()
+			if err = web.Links.Create(link); !errors.Is(err, links.ErrExists) {
+				break
+			}
+		}
+	}
+	if err != nil {
+		if errors.Is(err, links.ErrExists) {
+			writeJSON(w, http.StatusConflict, ErrorResponse{
+				Error:   err.Error(),
+				Message: "slug already taken",
+			})
+			return
+		}
+
+		slog.Error("creating link", "error", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   err.Error(),
+			Message: "could not create link",
+		})
+		return
+	}
+	slog.Info("created link", "id", link.ID, "expires_at", link.ExpiresAt)
+
+	writeJSON(w, http.StatusCreated, LinkResponse{
+		ID:        link.ID,
+		Path:      fmt.Sprintf("/s/%s", link.ID),
+		ExpiresAt: link.ExpiresAt,
+	})
+}
+
+func (web WebAPI) linkGet(w http.ResponseWriter, r *http.Request) {
+	request, err := web.linkRequest(chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, links.ErrNotFound) {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{
+				Error:   err.Error(),
+				Message: "unknown link",
+			})
+			return
+		}
+
+		slog.Error("loading link", "error", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   err.Error(),
+			Message: "could not load link",
+		})
+		return
+	}
+	request.Output = AchievementOutputType(r.URL.Query().Get("output"))
+
+	web.generateAndReturnAchievement(w, r, request)
+}
+
+// linkRequest returns the request stored for the link with the given ID.
+func (web WebAPI) linkRequest(id string) (AchievementRequest, error) {
+	link, err := web.Links.Get(id)
+	if err != nil {
+		return AchievementRequest{}, err
+	}
+
+	var request AchievementRequest
+	if err := json.Unmarshal(link.Request, &request); err != nil {
+		return AchievementRequest{}, fmt.Errorf("decoding link %s: %w", link.ID, err)
+	}
+	return request, nil
+}
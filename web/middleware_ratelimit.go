@@ -0,0 +1,196 @@
+package web
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/menzerath/mcgen/metrics"
+)
+
+// rateLimiterCleanupInterval defines how often buckets of idle clients are removed.
+const rateLimiterCleanupInterval = time.Minute
+
+// RateLimit configures a token bucket: clients may send Burst requests at once, which refill at Rate requests per second.
+// A Rate of zero disables the limit.
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// Enabled reports whether the limit is enforced.
+func (limit RateLimit) Enabled() bool {
+	return limit.Rate > 0
+}
+
+// RateLimits configures the rate limits for all routes.
+type RateLimits struct {
+	// Render limits all API routes for anonymous clients.
+	// Clients using an API key are limited by the key's own limit instead.
+	Render RateLimit
+
+	// Static limits the static files of the UI.
+	Static RateLimit
+
+	// TrustedProxies lists the networks of reverse proxies whose X-Forwarded-For header is used to determine the client's IP.
+	TrustedProxies []netip.Prefix
+}
+
+// clientIP returns the IP of the client that sent the request.
+// The X-Forwarded-For header is only followed as long as the request was forwarded by a trusted proxy.
+func (limits RateLimits) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	client, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0 && limits.trustedProxy(client); i-- {
+		previous, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+		client = previous
+	}
+
+	return client.Unmap().String()
+}
+
+// trustedProxy reports whether the given address belongs to a trusted proxy.
+func (limits RateLimits) trustedProxy(address netip.Addr) bool {
+	for _, prefix := range limits.TrustedProxies {
+		if prefix.Contains(address.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenBucket holds the remaining tokens of a single client.
+type tokenBucket struct {
+	limit   RateLimit
+	tokens  float64
+	updated time.Time
+}
+
+// full reports whether the bucket has been refilled completely at the given time.
+func (bucket *tokenBucket) full(now time.Time) bool {
+	return bucket.tokens+now.Sub(bucket.updated).Seconds()*bucket.limit.Rate >= float64(bucket.limit.Burst)
+}
+
+// rateLimiter keeps a token bucket per client.
+type rateLimiter struct {
+	// client returns the key identifying the client of a request and the limit to apply to it.
+	client func(r *http.Request) (string, RateLimit)
+
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+	lock        sync.Mutex
+}
+
+// newRateLimiter returns a rate limiter enforcing the limit returned for each client.
+func newRateLimiter(client func(r *http.Request) (string, RateLimit)) *rateLimiter {
+	return &rateLimiter{
+		client:      client,
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// take takes a token from the bucket of the given key, which is limited by the given limit.
+// It returns the remaining tokens, the duration until the bucket is full again and, if no token was left, how long to wait for the next one.
+func (limiter *rateLimiter) take(key string, limit RateLimit, now time.Time) (remaining int, reset time.Duration, retryAfter time.Duration) {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	limiter.cleanup(now)
+
+	// every client may send at least one request at once
+	limit.Burst = max(limit.Burst, 1)
+	burst := float64(limit.Burst)
+
+	bucket, exists := limiter.buckets[key]
+	if !exists || bucket.limit != limit {
+		bucket = &tokenBucket{limit: limit, tokens: burst, updated: now}
+		limiter.buckets[key] = bucket
+	}
+
+	// refill the bucket for the time passed since the last request
+	bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*limit.Rate)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		retryAfter = time.Duration((1 - bucket.tokens) / limit.Rate * float64(time.Second))
+	} else {
+		bucket.tokens--
+	}
+
+	reset = time.Duration((burst - bucket.tokens) / limit.Rate * float64(time.Second))
+	return int(bucket.tokens), reset, retryAfter
+}
+
+// cleanup removes the buckets of clients that have not sent any request for long enough to be full again.
+// It must be called while holding the lock.
+func (limiter *rateLimiter) cleanup(now time.Time) {
+	if now.Sub(limiter.lastCleanup) < rateLimiterCleanupInterval {
+		return
+	}
+	limiter.lastCleanup = now
+
+	for key, bucket := range limiter.buckets {
+		if bucket.full(now) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
+
+// middleware rejects requests of clients exceeding their rate limit with 429 Too Many Requests.
+func (limiter *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, limit := limiter.client(r)
+		if !limit.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		remaining, reset, retryAfter := limiter.take(key, limit, time.Now())
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(max(limit.Burst, 1)))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+
+		if retryAfter > 0 {
+			metrics.RateLimitedRequests.WithLabelValues(chi.RouteContext(r.Context()).RoutePattern()).Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeJSON(w, http.StatusTooManyRequests, ErrorResponse{
+				Error:   fmt.Sprintf("rate limit of %g requests per second exceeded", limit.Rate),
+				Message: "too many requests",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitMiddleware returns a middleware enforcing the given limit per client IP.
+// Clients authenticated by an API key are limited by the key's own limit instead.
+func (web WebAPI) rateLimitMiddleware(limit RateLimit) func(http.Handler) http.Handler {
+	return newRateLimiter(func(r *http.Request) (string, RateLimit) {
+		if key, ok := apiKeyFromContext(r.Context()); ok {
+			return "key:" + key.Name, key.RateLimit
+		}
+		return "ip:" + web.RateLimits.clientIP(r), limit
+	}).middleware
+}
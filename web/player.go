@@ -0,0 +1,130 @@
+package web
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/metrics"
+	"github.com/menzerath/mcgen/skins"
+)
+
+// playerGet renders the full body of the player given by name or UUID in the `player` parameter.
+func (web WebAPI) playerGet(w http.ResponseWriter, r *http.Request) {
+	options, err := playerQueryOptions(r.URL.Query())
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	player := r.URL.Query().Get("player")
+	if player == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "player must not be empty",
+			Message: "invalid player",
+		})
+		return
+	}
+	if web.Skins == nil {
+		writeRequestError(w, requestError{message: "skin lookups are disabled", err: errSkinsDisabled})
+		return
+	}
+	skin, err := web.Skins.Skin(r.Context(), player)
+	if err != nil {
+		writeAchievementError(w, r, err)
+		return
+	}
+	options.Skin = skin.Image
+	if options.Model == "" && skin.Slim {
+		options.Model = generator.PlayerSlim
+	}
+
+	web.generateAndReturnPlayer(w, r, options, AchievementOutputType(r.URL.Query().Get("output")))
+}
+
+// playerPost renders the full body of an uploaded skin.
+// It expects a multipart form with the skin in the "skin" field and the options in the regular fields.
+func (web WebAPI) playerPost(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSkinUploadSize)
+	if err := r.ParseMultipartForm(maxSkinUploadSize); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	file, _, err := r.FormFile("skin")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "missing skin",
+		})
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid skin",
+		})
+		return
+	}
+
+	options, err := playerQueryOptions(r.Form)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	options.Skin, err = skins.Decode(content)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid skin",
+		})
+		return
+	}
+
+	web.generateAndReturnPlayer(w, r, options, AchievementOutputType(r.FormValue("output")))
+}
+
+func (web WebAPI) generateAndReturnPlayer(w http.ResponseWriter, r *http.Request, options generator.PlayerOptions, output AchievementOutputType) {
+	timeStart := time.Now()
+	player, err := web.Generator.GeneratePlayer(r.Context(), options)
+	if err != nil {
+		writeGeneratorError(w, r, err, "could not generate player")
+		return
+	}
+	metrics.RenderRuntime.WithLabelValues("player").Observe(time.Since(timeStart).Seconds())
+	slog.Info(
+		"generated player",
+		"model", options.Model,
+		"view", options.View,
+		"pose", options.Pose,
+		"scale", options.Scale,
+		"runtime", time.Since(timeStart).Seconds(),
+	)
+
+	writeImage(w, output, "player.png", "image/png", player)
+}
+
+// playerQueryOptions parses the query parameters of the player API, except for the skin.
+func playerQueryOptions(query url.Values) (generator.PlayerOptions, error) {
+	options := generator.PlayerOptions{
+		Model: generator.PlayerModel(query.Get("model")),
+		View:  generator.PlayerView(query.Get("view")),
+		Pose:  generator.PlayerPose(query.Get("pose")),
+	}
+	if value := query.Get("scale"); value != "" {
+		var err error
+		if options.Scale, err = strconv.Atoi(value); err != nil {
+			return generator.PlayerOptions{}, requestError{message: "invalid scale", err: err}
+		}
+	}
+	return options, nil
+}
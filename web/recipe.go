@@ -0,0 +1,109 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/metrics"
+)
+
+// recipeGet renders the recipe given by its `type`, `grid`, `fuel`, `result` and `count` parameters.
+// The grid lists its rows separated by semicolons and their slots separated by commas.
+func (web WebAPI) recipeGet(w http.ResponseWriter, r *http.Request) {
+	request, err := recipeQueryRequest(r.URL.Query())
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	web.generateAndReturnRecipe(w, r, request)
+}
+
+func (web WebAPI) recipePost(w http.ResponseWriter, r *http.Request) {
+	var request RecipeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	web.generateAndReturnRecipe(w, r, request)
+}
+
+func (web WebAPI) generateAndReturnRecipe(w http.ResponseWriter, r *http.Request, request RecipeRequest) {
+	recipe, err := request.recipe()
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	timeStart := time.Now()
+	image, err := web.Generator.GenerateRecipe(r.Context(), recipe)
+	if err != nil {
+		writeGeneratorError(w, r, err, "could not generate recipe")
+		return
+	}
+	metrics.RenderRuntime.WithLabelValues("recipe").Observe(time.Since(timeStart).Seconds())
+	slog.Info(
+		"generated recipe",
+		"type", recipe.Type,
+		"result", recipe.Result,
+		"runtime", time.Since(timeStart).Seconds(),
+	)
+
+	writeImage(w, request.Output, "recipe.png", "image/png", image)
+}
+
+// recipeQueryRequest parses the query parameters of the recipe API.
+func recipeQueryRequest(query url.Values) (RecipeRequest, error) {
+	request := RecipeRequest{
+		Type:   generator.RecipeType(query.Get("type")),
+		Fuel:   query.Get("fuel"),
+		Result: query.Get("result"),
+		Output: AchievementOutputType(query.Get("output")),
+	}
+	if grid := query.Get("grid"); grid != "" {
+		for _, row := range strings.Split(grid, ";") {
+			request.Grid = append(request.Grid, strings.Split(row, ","))
+		}
+	}
+	if value := query.Get("count"); value != "" {
+		var err error
+		if request.Count, err = strconv.Atoi(value); err != nil {
+			return RecipeRequest{}, requestError{message: "invalid count", err: err}
+		}
+	}
+	return request, nil
+}
+
+// recipe converts the request into the recipe to render; a recipe file replaces all other fields.
+// Crafting is the default type.
+func (request RecipeRequest) recipe() (generator.Recipe, error) {
+	if len(request.Recipe) > 0 {
+		recipe, err := generator.ParseRecipe(request.Recipe)
+		if err != nil {
+			return generator.Recipe{}, requestError{message: "invalid recipe", err: err}
+		}
+		return recipe, nil
+	}
+
+	recipe := generator.Recipe{
+		Type:   request.Type,
+		Grid:   request.Grid,
+		Fuel:   request.Fuel,
+		Result: request.Result,
+		Count:  request.Count,
+	}
+	if recipe.Type == "" {
+		recipe.Type = generator.RecipeCrafting
+	}
+	return recipe, nil
+}
@@ -0,0 +1,186 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"image/color"
+	"net/url"
+	"strconv"
+
+	"github.com/menzerath/mcgen/generator"
+)
+
+// maxRequestSize limits the JSON bodies of the rendering APIs.
+const maxRequestSize = 64 << 10
+
+// errSkinsDisabled is returned for requests of players' heads if skins cannot be looked up.
+var errSkinsDisabled = errors.New("skin lookups are disabled")
+
+// errComponentFiltered is returned for text components containing text the content filter would mask.
+var errComponentFiltered = errors.New("text component contains filtered text")
+
+// errTextFiltered is returned for generated text the content filter would mask, e.g. the names of enchantments.
+var errTextFiltered = errors.New("text contains filtered words")
+
+// errUnpairedPatterns is returned for banners with a different number of patterns and colours.
+var errUnpairedPatterns = errors.New("every pattern needs a color")
+
+// filterAchievement applies the content filter to the title and text of the request.
+// Masked texts replace the original ones.
+func (web WebAPI) filterAchievement(request *AchievementRequest) error {
+	if web.Filter == nil {
+		return nil
+	}
+
+	var err error
+	if request.Title, err = web.Filter.Apply(request.Title); err != nil {
+		return requestError{message: "title rejected by content filter", err: err}
+	}
+	if request.Text, err = web.Filter.Apply(request.Text); err != nil {
+		return requestError{message: "text rejected by content filter", err: err}
+	}
+	return nil
+}
+
+// filterFormatted applies the content filter to the given text as it is shown, without the game's formatting codes,
+// so codes cannot split up filtered words. A masked text replaces the original one, losing its formatting.
+func (web WebAPI) filterFormatted(text string) (string, error) {
+	plain := generator.StripFormatting(text)
+	masked, err := web.Filter.Apply(plain)
+	if err != nil {
+		return "", err
+	}
+	if masked != plain {
+		return masked, nil
+	}
+	return text, nil
+}
+
+// legacyBackground maps the legacy icon ID to the new background name.
+// Anything else is passed on as it is, so background names and item IDs work as well.
+func (web WebAPI) legacyBackground(id string) string {
+	if background, exists := web.Generator.LegacyIcon(id); exists {
+		return background
+	}
+	return id
+}
+
+// legacyQueryRequest parses the query parameters of the legacy query API.
+func (web WebAPI) legacyQueryRequest(query url.Values) AchievementRequest {
+	background := web.legacyBackground(query.Get("i"))
+
+	// decide on the output type
+	output := AchievementOutputTypeDefault
+	if query.Get("d") == "1" {
+		output = AchievementOutputTypeDownload
+	}
+
+	return AchievementRequest{
+		Background: background,
+		Title:      query.Get("h"),
+		Text:       query.Get("t"),
+		Output:     output,
+	}
+}
+
+// legacyPathRequest parses the still escaped path parameters of the legacy path API.
+func (web WebAPI) legacyPathRequest(background string, title string, text string) (AchievementRequest, error) {
+	request := AchievementRequest{
+		Background: web.legacyBackground(background),
+		Output:     AchievementOutputTypeDefault,
+	}
+
+	// decode the title and text
+	var err error
+	if request.Title, err = url.QueryUnescape(title); err != nil {
+		return AchievementRequest{}, requestError{message: "invalid title", err: err}
+	}
+	if request.Text, err = url.QueryUnescape(text); err != nil {
+		return AchievementRequest{}, requestError{message: "invalid text", err: err}
+	}
+
+	return request, nil
+}
+
+// achievementQueryRequest parses the query parameters of the achievement API.
+func achievementQueryRequest(query url.Values) (AchievementRequest, error) {
+	request := AchievementRequest{
+		Background: query.Get("background"),
+		Title:      query.Get("title"),
+		Text:       query.Get("text"),
+		Enchanted:  query.Get("enchanted") == "true",
+		Tints:      query["tint"],
+		Animated:   query.Get("animated") == "true",
+		Player:     query.Get("player"),
+		Head:       generator.HeadStyle(query.Get("head")),
+		Banner:     query.Get("banner"),
+		Output:     AchievementOutputType(query.Get("output")),
+	}
+
+	var err error
+	if value := query.Get("count"); value != "" {
+		if request.Count, err = strconv.Atoi(value); err != nil {
+			return AchievementRequest{}, requestError{message: "invalid count", err: err}
+		}
+	}
+	if value := query.Get("durability"); value != "" {
+		durability, err := strconv.Atoi(value)
+		if err != nil {
+			return AchievementRequest{}, requestError{message: "invalid durability", err: err}
+		}
+		request.Durability = &durability
+	}
+	return request, nil
+}
+
+// achievement converts the request into the achievement to generate, looking up the player's skin if required.
+func (web WebAPI) achievement(ctx context.Context, request AchievementRequest) (generator.Achievement, error) {
+	achievement, err := request.achievement()
+	if err != nil || request.Player == "" {
+		return achievement, err
+	}
+	if web.Skins == nil {
+		return generator.Achievement{}, requestError{message: "player heads are disabled", err: errSkinsDisabled}
+	}
+
+	skin, err := web.Skins.Skin(ctx, request.Player)
+	if err != nil {
+		return generator.Achievement{}, err
+	}
+	achievement.Head = &generator.Head{Skin: skin.Image, Style: request.Head, Slim: skin.Slim}
+	return achievement, nil
+}
+
+// achievement converts the request into the achievement to generate, without a player's head.
+// Empty tints keep the colour of their layer.
+func (request AchievementRequest) achievement() (generator.Achievement, error) {
+	achievement := generator.Achievement{
+		Background: request.Background,
+		Title:      request.Title,
+		Text:       request.Text,
+		Icon: generator.IconModifiers{
+			Enchanted:  request.Enchanted,
+			Count:      request.Count,
+			Durability: request.Durability,
+		},
+	}
+	for _, tint := range request.Tints {
+		if tint == "" {
+			achievement.Icon.Tints = append(achievement.Icon.Tints, nil)
+			continue
+		}
+		c, err := generator.ParseHexColor(tint)
+		if err != nil {
+			return generator.Achievement{}, requestError{message: "invalid tint", err: err}
+		}
+		achievement.Icon.Tints = append(achievement.Icon.Tints, color.Color(c))
+	}
+	if request.Banner != "" {
+		banner, err := generator.ParseBannerItem(request.Banner)
+		if err != nil {
+			return generator.Achievement{}, requestError{message: "invalid banner", err: err}
+		}
+		achievement.Banner = &banner
+	}
+	return achievement, nil
+}
@@ -0,0 +1,93 @@
+package web
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/menzerath/mcgen/metrics"
+)
+
+// maxScreenshotUploadSize limits the size of uploaded screenshots in bytes.
+const maxScreenshotUploadSize = 32 << 20
+
+// screenshotPost places an achievement onto an uploaded screenshot.
+// It expects a multipart form with the screenshot in the "screenshot" field and the achievement in the regular fields.
+func (web WebAPI) screenshotPost(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxScreenshotUploadSize)
+	if err := r.ParseMultipartForm(maxScreenshotUploadSize); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	file, _, err := r.FormFile("screenshot")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "missing screenshot",
+		})
+		return
+	}
+	defer file.Close()
+	screenshot, err := io.ReadAll(file)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid screenshot",
+		})
+		return
+	}
+
+	// a missing gui scale means that we detect it from the screenshot's resolution
+	guiScale := 0
+	if value := r.FormValue("gui_scale"); value != "" && value != "auto" {
+		guiScale, err = strconv.Atoi(value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   err.Error(),
+				Message: "invalid gui scale",
+			})
+			return
+		}
+	}
+
+	// the remaining fields are the same as the achievement API's query parameters
+	request, err := achievementQueryRequest(r.Form)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	if err := web.filterAchievement(&request); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	achievement, err := web.achievement(r.Context(), request)
+	if err != nil {
+		writeAchievementError(w, r, err)
+		return
+	}
+
+	timeStart := time.Now()
+	result, format, err := web.Generator.GenerateOnScreenshot(r.Context(), screenshot, achievement, guiScale)
+	if err != nil {
+		writeGeneratorError(w, r, err, "could not generate screenshot")
+		return
+	}
+	metrics.AchievementGenerationRuntime.Observe(time.Since(timeStart).Seconds())
+	slog.Info(
+		"generated screenshot",
+		"background", request.Background,
+		"title", request.Title,
+		"text", request.Text,
+		"gui_scale", guiScale,
+		"runtime", time.Since(timeStart).Seconds(),
+	)
+
+	writeImage(w, request.Output, fmt.Sprintf("screenshot.%s", format), fmt.Sprintf("image/%s", format), result)
+}
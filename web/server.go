@@ -0,0 +1,167 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// Server configures the HTTP server.
+type Server struct {
+	Address           string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// ShutdownTimeout is the maximum duration to wait for in-flight requests while shutting down.
+	ShutdownTimeout time.Duration
+}
+
+// errServerStopped is returned for reloads after the server has stopped.
+var errServerStopped = errors.New("web api is not running")
+
+// serverReload requests to replace the settings of the running HTTP server.
+type serverReload struct {
+	server Server
+	result chan error
+}
+
+// runningServer is an HTTP server serving on its own listener.
+type runningServer struct {
+	settings Server
+	server   *http.Server
+	listener net.Listener
+	done     chan error
+}
+
+// listen starts serving the handler with the given settings.
+func listen(settings Server, handler http.Handler) (*runningServer, error) {
+	listener, err := net.Listen("tcp", settings.Address)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", settings.Address, err)
+	}
+
+	running := &runningServer{
+		settings: settings,
+		server: &http.Server{
+			Addr:              settings.Address,
+			Handler:           handler,
+			ReadTimeout:       settings.ReadTimeout,
+			ReadHeaderTimeout: settings.ReadHeaderTimeout,
+			WriteTimeout:      settings.WriteTimeout,
+			IdleTimeout:       settings.IdleTimeout,
+		},
+		listener: listener,
+		done:     make(chan error, 1),
+	}
+	go func() {
+		running.done <- running.server.Serve(listener)
+	}()
+
+	slog.Info("web api listening", "address", settings.Address)
+	return running, nil
+}
+
+// shutdown stops accepting new connections and waits for in-flight requests to finish.
+func (running *runningServer) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), running.settings.ShutdownTimeout)
+	defer cancel()
+
+	if err := running.server.Shutdown(ctx); err != nil {
+		slog.Error("stopping web api failed", "address", running.settings.Address, "error", err)
+	}
+}
+
+// ReloadServer replaces the settings of the running HTTP server without dropping in-flight requests.
+// If the address changes, the new address is listened on before the old one is closed.
+// It keeps the old server running and returns an error if the new one cannot be started or the server has stopped.
+func (web WebAPI) ReloadServer(server Server) error {
+	result := make(chan error, 1)
+	select {
+	case web.reloads <- serverReload{server: server, result: result}:
+		return <-result
+	case <-web.stopped:
+		return errServerStopped
+	}
+}
+
+// serve serves the handler and handles reloads until the server is interrupted.
+func (web WebAPI) serve(handler http.Handler) {
+	defer close(web.stopped)
+
+	current, err := listen(web.Server, handler)
+	if err != nil {
+		slog.Error("web api listening", "error", err)
+		return
+	}
+
+	// enable a graceful shutdown
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	for {
+		select {
+		case <-interrupt:
+			slog.Info("stopping web api")
+			current.shutdown()
+			slog.Warn("web api stopped")
+			return
+
+		case err := <-current.done:
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("web api listening", "error", err)
+			}
+			slog.Warn("web api stopped")
+			return
+
+		case reload := <-web.reloads:
+			// even a failed reload may have replaced the server while restoring the current settings
+			replacement, err := replace(current, reload.server, handler)
+			if replacement != nil {
+				current = replacement
+			}
+			reload.result <- err
+		}
+	}
+}
+
+// replace starts a new server with the given settings and gracefully shuts down the current one.
+// It returns the server now serving requests, if any.
+func replace(current *runningServer, settings Server, handler http.Handler) (*runningServer, error) {
+	if settings == current.settings {
+		return current, nil
+	}
+
+	// a different address can be listened on while the current server is still running
+	if settings.Address != current.settings.Address {
+		replacement, err := listen(settings, handler)
+		if err != nil {
+			return nil, err
+		}
+		go current.shutdown()
+		return replacement, nil
+	}
+
+	// the same address has to be released first, which only refuses new connections for a moment
+	_ = current.listener.Close()
+	replacement, err := listen(settings, handler)
+	if err != nil {
+		// try to restore the current settings, so we keep serving
+		restored, restoreErr := listen(current.settings, handler)
+		if restoreErr != nil {
+			return nil, errors.Join(err, restoreErr)
+		}
+		go current.shutdown()
+		return restored, err
+	}
+	go current.shutdown()
+
+	return replacement, nil
+}
@@ -0,0 +1,201 @@
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/menzerath/mcgen/metrics"
+)
+
+// query parameters used by signed URLs
+const (
+	signatureExpiryParameter = "exp"
+	signatureParameter       = "sig"
+)
+
+// maxSignatureLifetime limits how far in the future signed URLs may expire.
+const maxSignatureLifetime = 365 * 24 * time.Hour
+
+// Signing configures signed URLs and hotlink protection.
+// Protected routes are only enforced if Secret is set.
+type Signing struct {
+	// Secret is the key used to sign URLs.
+	Secret []byte
+
+	// Token allows trusted callers to sign URLs and to access protected routes without a signature.
+	Token string
+
+	// TrustOrigins allows requests from the same host as the API and from AllowedOrigins to access protected routes without a signature.
+	// Origins are taken from the Origin and Referer headers, which browsers set but any other client can forge;
+	// this keeps other sites from embedding images, but offers no protection against scripted clients.
+	TrustOrigins bool
+
+	// AllowedOrigins lists origins (like `https://example.com`) allowed to access protected routes without a signature if TrustOrigins is set.
+	AllowedOrigins []string
+}
+
+// Enabled reports whether protected routes require a signature.
+func (signing Signing) Enabled() bool {
+	return len(signing.Secret) > 0
+}
+
+// signature returns the signature of the given URL, ignoring any existing signature parameter.
+// Only the escaped path and the sorted query parameters are covered, so it does not matter which host serves the URL.
+func (signing Signing) signature(target *url.URL) string {
+	query := target.Query()
+	query.Del(signatureParameter)
+
+	mac := hmac.New(sha256.New, signing.Secret)
+	_, _ = fmt.Fprintf(mac, "%s\n%s", target.EscapedPath(), query.Encode())
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign adds an expiry and a signature to the given URL.
+func (signing Signing) Sign(target *url.URL, expiresAt time.Time) {
+	query := target.Query()
+	query.Set(signatureExpiryParameter, strconv.FormatInt(expiresAt.Unix(), 10))
+	target.RawQuery = query.Encode()
+
+	query.Set(signatureParameter, signing.signature(target))
+	target.RawQuery = query.Encode()
+}
+
+// verify checks the signature of the given URL and returns the reason if it is not valid.
+func (signing Signing) verify(target *url.URL, now time.Time) (string, bool) {
+	query := target.Query()
+	if query.Get(signatureParameter) == "" || query.Get(signatureExpiryParameter) == "" {
+		return "missing", false
+	}
+
+	expiresAt, err := strconv.ParseInt(query.Get(signatureExpiryParameter), 10, 64)
+	if err != nil {
+		return "invalid", false
+	}
+	if !hmac.Equal([]byte(query.Get(signatureParameter)), []byte(signing.signature(target))) {
+		return "invalid", false
+	}
+	if now.Unix() >= expiresAt {
+		return "expired", false
+	}
+
+	return "", true
+}
+
+// trusted reports whether the request carries the token of a trusted caller.
+func (signing Signing) trusted(r *http.Request) bool {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return found && signing.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(signing.Token)) == 1
+}
+
+// allowedOrigin reports whether the request was sent by a page of an allowed origin, according to its Origin or Referer header.
+// It always reports false unless origins are trusted, as the headers can be forged.
+func (signing Signing) allowedOrigin(r *http.Request) bool {
+	if !signing.TrustOrigins {
+		return false
+	}
+
+	source := r.Header.Get("Origin")
+	if source == "" || source == "null" {
+		source = r.Header.Get("Referer")
+	}
+	if source == "" {
+		return false
+	}
+
+	parsed, err := url.Parse(source)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	if parsed.Host == r.Host {
+		return true
+	}
+
+	origin := fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
+	for _, allowed := range signing.AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// signatureMiddleware rejects requests to protected routes unless they are signed, sent by a trusted caller or come from an allowed origin.
+func (web WebAPI) signatureMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !web.Signing.Enabled() || web.Signing.trusted(r) || web.Signing.allowedOrigin(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// only image URLs can be signed, all other requests have to come from trusted callers or allowed origins
+		reason, valid := "missing", false
+		if r.Method == http.MethodGet {
+			reason, valid = web.Signing.verify(r.URL, time.Now())
+		}
+		if !valid {
+			metrics.SignatureRejections.WithLabelValues(reason).Inc()
+			writeJSON(w, http.StatusForbidden, ErrorResponse{
+				Error:   fmt.Sprintf("%s signature", reason),
+				Message: "this route requires a signed url",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// signPost signs the given URL for trusted callers.
+func (web WebAPI) signPost(w http.ResponseWriter, r *http.Request) {
+	if !web.Signing.trusted(r) {
+		writeJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "missing or invalid token",
+			Message: "signing urls requires a valid token",
+		})
+		return
+	}
+
+	var request SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	lifetime := time.Duration(request.ExpiresIn) * time.Second
+	if lifetime <= 0 || lifetime > maxSignatureLifetime {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   fmt.Sprintf("expires_in must be between 1 and %d seconds", int64(maxSignatureLifetime.Seconds())),
+			Message: "invalid expiry",
+		})
+		return
+	}
+
+	target, err := url.Parse(request.URL)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid url",
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(lifetime).Truncate(time.Second)
+	web.Signing.Sign(target, expiresAt)
+
+	writeJSON(w, http.StatusOK, SignResponse{
+		URL:       target.String(),
+		ExpiresAt: expiresAt.UTC(),
+	})
+}
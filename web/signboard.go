@@ -0,0 +1,88 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/metrics"
+)
+
+// signboardGet renders the sign given by its `wood`, up to four `line` parameters, `color`, `glowing` and `hanging`.
+func (web WebAPI) signboardGet(w http.ResponseWriter, r *http.Request) {
+	web.generateAndReturnSignboard(w, r, signboardQueryRequest(r.URL.Query()))
+}
+
+func (web WebAPI) signboardPost(w http.ResponseWriter, r *http.Request) {
+	var request SignboardRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	web.generateAndReturnSignboard(w, r, request)
+}
+
+func (web WebAPI) generateAndReturnSignboard(w http.ResponseWriter, r *http.Request, request SignboardRequest) {
+	if err := web.filterSignboard(&request); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	timeStart := time.Now()
+	image, err := web.Generator.GenerateSign(r.Context(), generator.Sign{
+		Wood:    request.Wood,
+		Hanging: request.Hanging,
+		Lines:   request.Lines,
+		Color:   request.Color,
+		Glowing: request.Glowing,
+	})
+	if err != nil {
+		writeGeneratorError(w, r, err, "could not generate sign")
+		return
+	}
+	metrics.RenderRuntime.WithLabelValues("sign").Observe(time.Since(timeStart).Seconds())
+	slog.Info(
+		"generated sign",
+		"wood", request.Wood,
+		"hanging", request.Hanging,
+		"lines", len(request.Lines),
+		"runtime", time.Since(timeStart).Seconds(),
+	)
+
+	writeImage(w, request.Output, "sign.png", "image/png", image)
+}
+
+// signboardQueryRequest parses the query parameters of the sign API.
+func signboardQueryRequest(query url.Values) SignboardRequest {
+	return SignboardRequest{
+		Wood:    query.Get("wood"),
+		Hanging: query.Get("hanging") == "true",
+		Lines:   query["line"],
+		Color:   query.Get("color"),
+		Glowing: query.Get("glowing") == "true",
+		Output:  AchievementOutputType(query.Get("output")),
+	}
+}
+
+// filterSignboard applies the content filter to the lines of the request as they are shown.
+// Masked texts replace the original ones and lose their formatting.
+func (web WebAPI) filterSignboard(request *SignboardRequest) error {
+	if web.Filter == nil {
+		return nil
+	}
+
+	var err error
+	for i := range request.Lines {
+		if request.Lines[i], err = web.filterFormatted(request.Lines[i]); err != nil {
+			return requestError{message: "line rejected by content filter", err: err}
+		}
+	}
+	return nil
+}
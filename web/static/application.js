@@ -7,6 +7,7 @@ const achievement = document.getElementById('achievement');
 const boxURL = document.getElementById('out-url');
 const boxHTML = document.getElementById('out-html');
 const boxBB = document.getElementById('out-bb');
+const buttonShorten = document.getElementById('shorten');
 
 // handle input change by updating the image
 title.oninput = updateImageAfterTimeout;
@@ -28,6 +29,9 @@ boxBB.onclick = function () {
     copyURL(boxBB);
 }
 
+// replace the links with a short link on click
+buttonShorten.onclick = shortenURL;
+
 // download image on click
 achievement.onclick = function () {
     window.location.href = achievement.src + '&output=download';
@@ -43,10 +47,39 @@ function updateImageAfterTimeout() {
 // update image and boxes
 function updateImage() {
     achievement.src = `api/v1/achievement?background=${background.value}&title=${encodeURIComponent(title.value)}&text=${encodeURIComponent(text.value)}`;
+    updateBoxes(achievement.src);
+}
+
+// update link boxes to point to the given image url
+function updateBoxes(url) {
+    boxURL.value = url;
+    boxHTML.value = `<a href="${window.location.href}" target="_blank"><img src="${url}" alt="Minecraft Achievement" /></a>`;
+    boxBB.value = `[url=${window.location.href}][img]${url}[/img][/url]`;
+}
+
+// create a short link for the current achievement and show it in the link boxes
+async function shortenURL() {
+    const response = await fetch('api/v1/links', {
+        method: 'POST',
+        headers: {'Content-Type': 'application/json'},
+        body: JSON.stringify({
+            background: background.value,
+            title: title.value,
+            text: text.value,
+        }),
+    });
+
+    // short links are disabled on this instance
+    if (response.status === 404) {
+        buttonShorten.hidden = true;
+        return;
+    }
+    if (!response.ok) {
+        return;
+    }
 
-    boxURL.value = achievement.src;
-    boxHTML.value = `<a href="${window.location.href}" target="_blank"><img src="${achievement.src}" alt="Minecraft Achievement" /></a>`;
-    boxBB.value = `[url=${window.location.href}][img]${achievement.src}[/img][/url]`;
+    const link = await response.json();
+    updateBoxes(new URL(link.path, window.location.href).href);
 }
 
 // copy input field content to clipboard
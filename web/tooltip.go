@@ -0,0 +1,87 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/metrics"
+)
+
+func (web WebAPI) tooltipPost(w http.ResponseWriter, r *http.Request) {
+	var request TooltipRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   err.Error(),
+			Message: "invalid request body",
+		})
+		return
+	}
+	if err := web.filterTooltip(&request); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	timeStart := time.Now()
+	image, err := web.Generator.GenerateTooltip(r.Context(), request.tooltip())
+	if err != nil {
+		writeGeneratorError(w, r, err, "could not generate tooltip")
+		return
+	}
+	metrics.RenderRuntime.WithLabelValues("tooltip").Observe(time.Since(timeStart).Seconds())
+	slog.Info(
+		"generated tooltip",
+		"name", request.Name,
+		"lines", 1+len(request.Enchantments)+len(request.Lore)+len(request.Attributes),
+		"runtime", time.Since(timeStart).Seconds(),
+	)
+
+	writeImage(w, request.Output, "tooltip.png", "image/png", image)
+}
+
+// tooltip converts the request into the tooltip to render.
+func (request TooltipRequest) tooltip() generator.Tooltip {
+	return generator.Tooltip{
+		Name:         request.Name,
+		Rarity:       request.Rarity,
+		Enchantments: request.Enchantments,
+		Lore:         request.Lore,
+		Attributes:   request.Attributes,
+	}
+}
+
+// filterTooltip applies the content filter to all lines of the tooltip as they are shown.
+// Masked names and lore replace the original ones; as names of enchantments and attributes cannot be masked, they are rejected instead.
+func (web WebAPI) filterTooltip(request *TooltipRequest) error {
+	if web.Filter == nil {
+		return nil
+	}
+
+	var err error
+	if request.Name, err = web.filterFormatted(request.Name); err != nil {
+		return requestError{message: "name rejected by content filter", err: err}
+	}
+	for i := range request.Lore {
+		if request.Lore[i], err = web.filterFormatted(request.Lore[i]); err != nil {
+			return requestError{message: "lore rejected by content filter", err: err}
+		}
+	}
+
+	// invalid tooltips are reported by the generator
+	lines, err := request.tooltip().Text()
+	if err != nil {
+		return nil
+	}
+	for _, line := range lines {
+		masked, err := web.Filter.Apply(line)
+		if err == nil && masked != line {
+			err = errTextFiltered
+		}
+		if err != nil {
+			return requestError{message: "tooltip rejected by content filter", err: err}
+		}
+	}
+	return nil
+}
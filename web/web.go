@@ -2,33 +2,100 @@
 package web
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"io/fs"
 	"log/slog"
 	"net/http"
-	"net/url"
 	"os"
-	"os/signal"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/menzerath/mcgen/assets"
+	"github.com/menzerath/mcgen/filter"
 	"github.com/menzerath/mcgen/generator"
+	"github.com/menzerath/mcgen/links"
 	"github.com/menzerath/mcgen/metrics"
+	"github.com/menzerath/mcgen/skins"
 )
 
 // WebAPI provides a web API for the generator.
 type WebAPI struct {
 	Generator *generator.Generator
+
+	// Links stores short links; they are disabled if it is nil.
+	Links links.Store
+
+	// Build identifies the build of mcgen and is recorded in immutable URLs.
+	Build string
+
+	// Signing configures signed URLs and hotlink protection.
+	Signing Signing
+
+	// RateLimits configures the rate limits per client.
+	RateLimits RateLimits
+
+	// APIKeys configures authentication by API keys.
+	APIKeys APIKeys
+
+	// Skins looks up the skins of players shown as icons; heads of players are disabled if it is nil.
+	Skins *skins.Service
+
+	// Filter checks all texts before they are rendered; nothing is filtered if it is nil.
+	Filter filter.Filter
+
+	// Server configures the HTTP server.
+	Server Server
+
+	// Features toggles optional parts of the API.
+	Features Features
+
+	reloads chan serverReload
+	stopped chan struct{}
+}
+
+// Features toggles optional parts of the API.
+type Features struct {
+	UI         bool
+	Collage    bool
+	Screenshot bool
+	Immutable  bool
+	Heads      bool
+	Players    bool
+	Banners    bool
+	Recipes    bool
+	Tooltips   bool
+	Chat       bool
+	Signboards bool
 }
 
 // New returns a new WebAPI.
+// It listens on port 8080, allows anonymous access and enables all features by default.
 func New(generator *generator.Generator) WebAPI {
 	return WebAPI{
 		Generator: generator,
+		APIKeys: APIKeys{
+			Anonymous: true,
+		},
+		Server: Server{
+			Address:         ":8080",
+			ShutdownTimeout: 5 * time.Second,
+		},
+		Features: Features{
+			UI:         true,
+			Collage:    true,
+			Screenshot: true,
+			Immutable:  true,
+			Heads:      true,
+			Players:    true,
+			Banners:    true,
+			Recipes:    true,
+			Tooltips:   true,
+			Chat:       true,
+			Signboards: true,
+		},
+		reloads: make(chan serverReload),
+		stopped: make(chan struct{}),
 	}
 }
 
@@ -45,10 +112,80 @@ func (web WebAPI) StartWebAPI() {
 	r.Use(slogLoggingMiddleware)
 
 	// register API routes
-	r.Get("/a.php", web.legacyAPIQuery)
-	r.Get("/a/{background}/{title}/{text}", web.legacyAPIPath)
-	r.Get("/api/v1/achievement", web.achievementGet)
-	r.Post("/api/v1/achievement", web.achievementPost)
+	keyring := newAPIKeyring(web.APIKeys)
+	r.Group(func(r chi.Router) {
+		r.Use(keyring.authenticate)
+		r.Use(web.rateLimitMiddleware(web.RateLimits.Render))
+
+		// routes rendering images may require a signature
+		r.Group(func(r chi.Router) {
+			r.Use(web.signatureMiddleware)
+
+			r.With(keyring.feature(FeatureAchievement)).Get("/a.php", web.legacyAPIQuery)
+			r.With(keyring.feature(FeatureAchievement)).Get("/a/{background}/{title}/{text}", web.legacyAPIPath)
+			r.With(keyring.feature(FeatureAchievement)).Get("/api/v1/achievement", web.achievementGet)
+			r.With(keyring.feature(FeatureAchievement)).Post("/api/v1/achievement", web.achievementPost)
+			if web.Features.Collage {
+				r.With(keyring.feature(FeatureCollage)).Get("/api/v1/collage", web.collageGet)
+				r.With(keyring.feature(FeatureCollage)).Post("/api/v1/collage", web.collagePost)
+			}
+			if web.Features.Screenshot {
+				r.With(keyring.feature(FeatureScreenshot)).Post("/api/v1/screenshot", web.screenshotPost)
+			}
+			if web.Features.Heads {
+				r.With(keyring.feature(FeatureHeads)).Post("/api/v1/head", web.headPost)
+			}
+			if web.Features.Players {
+				r.With(keyring.feature(FeaturePlayers)).Get("/api/v1/player", web.playerGet)
+				r.With(keyring.feature(FeaturePlayers)).Post("/api/v1/player", web.playerPost)
+			}
+			if web.Features.Banners {
+				r.With(keyring.feature(FeatureBanners)).Get("/api/v1/banner", web.bannerGet)
+				r.With(keyring.feature(FeatureBanners)).Post("/api/v1/banner", web.bannerPost)
+			}
+			if web.Features.Recipes {
+				r.With(keyring.feature(FeatureRecipes)).Get("/api/v1/recipe", web.recipeGet)
+				r.With(keyring.feature(FeatureRecipes)).Post("/api/v1/recipe", web.recipePost)
+			}
+			if web.Features.Tooltips {
+				r.With(keyring.feature(FeatureTooltips)).Post("/api/v1/tooltip", web.tooltipPost)
+			}
+			if web.Features.Chat {
+				r.With(keyring.feature(FeatureChat)).Post("/api/v1/chat", web.chatPost)
+			}
+			if web.Features.Signboards {
+				r.With(keyring.feature(FeatureSignboards)).Get("/api/v1/signboard", web.signboardGet)
+				r.With(keyring.feature(FeatureSignboards)).Post("/api/v1/signboard", web.signboardPost)
+			}
+			if web.Features.Immutable {
+				r.With(keyring.feature(FeatureImmutable)).Get("/r/{spec}.{ext}", web.renderGet)
+			}
+			if web.Links != nil {
+				r.With(keyring.feature(FeatureLinks)).Get("/s/{id}", web.linkGet)
+			}
+		})
+
+		if web.Features.Immutable {
+			r.With(keyring.feature(FeatureImmutable)).Get("/api/v1/immutable", web.immutableGet)
+			r.With(keyring.feature(FeatureImmutable)).Post("/api/v1/immutable", web.immutablePost)
+		}
+		if web.Links != nil {
+			r.With(keyring.feature(FeatureLinks)).Post("/api/v1/links", web.linkPost)
+		}
+		if web.Signing.Token != "" {
+			r.Post("/api/v1/sign", web.signPost)
+		}
+	})
+	if web.APIKeys.AdminToken != "" {
+		r.Group(func(r chi.Router) {
+			r.Use(keyring.admin)
+
+			r.Get("/api/v1/admin/keys", keyring.usageGet)
+			r.Get("/api/v1/admin/assets", web.assetsGet)
+			r.Put("/api/v1/admin/assets/{kind}/{name}", web.assetPut)
+			r.Delete("/api/v1/admin/assets/{kind}/{name}", web.assetDelete)
+		})
+	}
 
 	// serve embedded static files for requests that don't match any API route
 	subFS, err := fs.Sub(static, "static")
@@ -59,50 +196,19 @@ func (web WebAPI) StartWebAPI() {
 	fileServer := http.FileServer(http.FS(subFS))
 
 	// serve static files; fall back to a custom 404 for anything else
-	r.Handle("/*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	r.With(web.rateLimitMiddleware(web.RateLimits.Static)).Handle("/*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/")
 		if path == "" {
 			path = "."
 		}
-		if _, err := subFS.Open(path); err == nil {
+		if _, err := subFS.Open(path); err == nil && web.Features.UI {
 			fileServer.ServeHTTP(w, r)
 			return
 		}
 		http.Error(w, `Whatever you are looking for, it's not here ¯\_(ツ)_/¯`, http.StatusNotFound)
 	}))
 
-	// determine the port to listen on
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%s", port),
-		Handler: r,
-	}
-
-	// enable a graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	go func() {
-		_ = <-c
-
-		slog.Info("stopping web api")
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if err := srv.Shutdown(ctx); err != nil {
-			slog.Error("stopping web api failed", "error", err)
-		}
-	}()
-
-	// listen on configured port
-	slog.Info("web api starting", "port", port)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		slog.Error("web api listening", "error", err)
-	}
-	slog.Warn("web api stopped")
+	web.serve(r)
 }
 
 // writeJSON writes v as JSON with the given HTTP status code.
@@ -113,65 +219,32 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 }
 
 func (web WebAPI) legacyAPIQuery(w http.ResponseWriter, r *http.Request) {
-	// map the legacy icon ID to the new background name
-	background, _ := assets.LegacyIconMappings[r.URL.Query().Get("i")]
-
-	// decide on the output type
-	output := AchievementOutputTypeDefault
-	if r.URL.Query().Get("d") == "1" {
-		output = AchievementOutputTypeDownload
-	}
-
-	web.generateAndReturnAchievement(w, r, AchievementRequest{
-		Background: background,
-		Title:      r.URL.Query().Get("h"),
-		Text:       r.URL.Query().Get("t"),
-		Output:     output,
-	})
+	web.generateAndReturnAchievement(w, r, web.legacyQueryRequest(r.URL.Query()))
 }
 
 func (web WebAPI) legacyAPIPath(w http.ResponseWriter, r *http.Request) {
-	// map the legacy icon ID to the new background name
-	background, _ := assets.LegacyIconMappings[chi.URLParam(r, "background")]
-
-	// decode the title and text
-	title, err := url.QueryUnescape(chi.URLParam(r, "title"))
+	request, err := web.legacyPathRequest(chi.URLParam(r, "background"), chi.URLParam(r, "title"), chi.URLParam(r, "text"))
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, ErrorResponse{
-			Error:   err.Error(),
-			Message: "invalid title",
-		})
-		return
-	}
-	text, err := url.QueryUnescape(chi.URLParam(r, "text"))
-	if err != nil {
-		writeJSON(w, http.StatusBadRequest, ErrorResponse{
-			Error:   err.Error(),
-			Message: "invalid text",
-		})
+		writeRequestError(w, err)
 		return
 	}
 
-	web.generateAndReturnAchievement(w, r, AchievementRequest{
-		Background: background,
-		Title:      title,
-		Text:       text,
-		Output:     AchievementOutputTypeDefault,
-	})
+	web.generateAndReturnAchievement(w, r, request)
 }
 
 func (web WebAPI) achievementGet(w http.ResponseWriter, r *http.Request) {
-	web.generateAndReturnAchievement(w, r, AchievementRequest{
-		Background: r.URL.Query().Get("background"),
-		Title:      r.URL.Query().Get("title"),
-		Text:       r.URL.Query().Get("text"),
-		Output:     AchievementOutputType(r.URL.Query().Get("output")),
-	})
+	request, err := achievementQueryRequest(r.URL.Query())
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	web.generateAndReturnAchievement(w, r, request)
 }
 
 func (web WebAPI) achievementPost(w http.ResponseWriter, r *http.Request) {
 	var request AchievementRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
 		writeJSON(w, http.StatusBadRequest, ErrorResponse{
 			Error:   err.Error(),
 			Message: "invalid request body",
@@ -183,22 +256,34 @@ func (web WebAPI) achievementPost(w http.ResponseWriter, r *http.Request) {
 }
 
 func (web WebAPI) generateAndReturnAchievement(w http.ResponseWriter, r *http.Request, request AchievementRequest) {
-	timeStart := time.Now()
-	achievement, err := web.Generator.Generate(request.Background, request.Title, request.Text)
+	if err := web.filterAchievement(&request); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	achievement, err := web.achievement(r.Context(), request)
 	if err != nil {
-		if err == generator.ErrUnknownBackground {
-			writeJSON(w, http.StatusBadRequest, ErrorResponse{
-				Error:   err.Error(),
-				Message: "unknown background",
-			})
-			return
-		}
+		writeAchievementError(w, r, err)
+		return
+	}
 
-		slog.Error("generating image", "error", err)
-		writeJSON(w, http.StatusInternalServerError, ErrorResponse{
-			Error:   err.Error(),
-			Message: "could not generate achievement",
-		})
+	web.returnAchievement(w, r, request, achievement)
+}
+
+// returnAchievement generates the given achievement and writes it in the format and output requested.
+func (web WebAPI) returnAchievement(w http.ResponseWriter, r *http.Request, request AchievementRequest, achievement generator.Achievement) {
+	timeStart := time.Now()
+	filename, contentType := "achievement.png", "image/png"
+	var result []byte
+	var err error
+	if request.Animated {
+		filename, contentType = "achievement.gif", "image/gif"
+		result, err = web.Generator.GenerateAnimated(r.Context(), achievement)
+	} else {
+		result, err = web.Generator.GenerateAchievement(r.Context(), achievement)
+	}
+	if err != nil {
+		writeGeneratorError(w, r, err, "could not generate achievement")
 		return
 	}
 	metrics.AchievementGenerationRuntime.Observe(time.Since(timeStart).Seconds())
@@ -207,21 +292,30 @@ func (web WebAPI) generateAndReturnAchievement(w http.ResponseWriter, r *http.Re
 		"background", request.Background,
 		"title", request.Title,
 		"text", request.Text,
+		"enchanted", request.Enchanted,
+		"tints", request.Tints,
+		"animated", request.Animated,
+		"player", request.Player,
 		"runtime", time.Since(timeStart).Seconds(),
 	)
 
+	writeImage(w, request.Output, filename, contentType, result)
+}
+
+// writeImage writes the given image either inline or as a download with the given filename.
+func writeImage(w http.ResponseWriter, output AchievementOutputType, filename string, contentType string, image []byte) {
 	// return image as download
-	if request.Output == AchievementOutputTypeDownload {
+	if output == AchievementOutputTypeDownload {
 		w.Header().Set("Content-Description", "File Transfer")
 		w.Header().Set("Content-Type", "application/octet-image")
-		w.Header().Set("Content-Disposition", "attachment; filename=achievement.png")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write(achievement)
+		_, _ = w.Write(image)
 		return
 	}
 
 	// return image in response
-	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(achievement)
+	_, _ = w.Write(image)
 }